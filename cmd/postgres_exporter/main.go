@@ -17,15 +17,15 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
-	"github.com/prometheus-community/postgres_exporter/collector"
 	"github.com/prometheus-community/postgres_exporter/config"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/promlog"
 	"github.com/prometheus/common/promlog/flag"
 	"github.com/prometheus/common/version"
@@ -81,7 +81,7 @@ func main() {
 		return
 	}
 
-	if err := c.ReloadConfig(*configFile, logger); err != nil {
+	if err := c.ReloadConfig(*configFile, logger, validateTargetCollectors); err != nil {
 		// This is not fatal, but it means that auth must be provided for every dsn.
 		level.Warn(logger).Log("msg", "Error loading config", "err", err)
 	}
@@ -132,19 +132,27 @@ func main() {
 		dsn = dsns[0]
 	}
 
-	pe, err := collector.NewPostgresCollector(
-		logger,
-		excludedDatabases,
-		dsn,
-		[]string{},
-	)
-	if err != nil {
+	mh := newMetricsHandler(dsn, excludedDatabases)
+	if err := mh.reload(c.GetConfig()); err != nil {
 		level.Warn(logger).Log("msg", "Failed to create PostgresCollector", "err", err.Error())
-	} else {
-		prometheus.MustRegister(pe)
 	}
 
-	http.Handle(*metricsPath, promhttp.Handler())
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			// The /metrics collectors are rebuilt before the new config is
+			// put in place, so a config they reject is not served by /probe
+			// either.
+			if err := c.ReloadConfig(*configFile, logger, validateTargetCollectors, mh.reload); err != nil {
+				level.Error(logger).Log("msg", "Error reloading config, keeping previous config", "err", err)
+				continue
+			}
+			level.Info(logger).Log("msg", "Reloaded config file", "file", *configFile)
+		}
+	}()
+
+	http.Handle(*metricsPath, mh)
 
 	if *metricsPath != "/" && *metricsPath != "" {
 		landingConfig := web.LandingConfig{
@@ -0,0 +1,83 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus-community/postgres_exporter/collector"
+	"github.com/prometheus-community/postgres_exporter/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsHandler serves the /metrics endpoint. The PostgresCollector is kept in
+// its own registry which is rebuilt whenever the config file is reloaded, so
+// the enabled collectors and constant labels can change without a restart.
+// Scrapes that are already running complete against the previous registry.
+type metricsHandler struct {
+	sync.RWMutex
+	handler http.Handler
+
+	dsn              string
+	excludeDatabases []string
+}
+
+func newMetricsHandler(dsn string, excludeDatabases []string) *metricsHandler {
+	return &metricsHandler{
+		handler:          promhttp.Handler(),
+		dsn:              dsn,
+		excludeDatabases: excludeDatabases,
+	}
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.RLock()
+	handler := h.handler
+	h.RUnlock()
+	handler.ServeHTTP(w, r)
+}
+
+// reload builds a new PostgresCollector from conf and swaps it in. The current
+// handler is left in place if the collector can not be created. Without a DSN
+// only /probe is served, so there is nothing to rebuild.
+func (h *metricsHandler) reload(conf *config.Config) error {
+	if h.dsn == "" {
+		return nil
+	}
+
+	pe, err := collector.NewPostgresCollector(
+		logger,
+		h.excludeDatabases,
+		h.dsn,
+		conf.Metrics.Collectors,
+	)
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+	prometheus.WrapRegistererWith(conf.Metrics.Labels, registry).MustRegister(pe)
+
+	handler := promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, registry}, promhttp.HandlerOpts{}),
+	)
+
+	h.Lock()
+	h.handler = handler
+	h.Unlock()
+	return nil
+}
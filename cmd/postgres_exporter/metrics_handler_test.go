@@ -0,0 +1,42 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus-community/postgres_exporter/config"
+)
+
+func TestMetricsHandlerReloadWithoutDSN(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "postgres_exporter.yml")
+	if err := os.WriteFile(f, []byte("auth_modules:\n  first:\n    type: userpass\n    userpass:\n      username: first\n      password: firstpass\n"), 0o600); err != nil {
+		t.Fatalf("Error writing config file: %s", err)
+	}
+
+	ch := &config.Handler{Config: &config.Config{}}
+	mh := newMetricsHandler("", nil)
+	if err := ch.ReloadConfig(f, log.NewNopLogger(), mh.reload); err != nil {
+		t.Fatalf("Error reloading config without a DSN: %s", err)
+	}
+	if _, ok := ch.GetConfig().AuthModules["first"]; !ok {
+		t.Errorf("Reloaded config is missing auth module %q", "first")
+	}
+}
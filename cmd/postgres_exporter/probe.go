@@ -14,6 +14,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -35,8 +36,13 @@ func handleProbe(logger log.Logger, excludeDatabases []string) http.HandlerFunc
 			http.Error(w, "target is required", http.StatusBadRequest)
 			return
 		}
+		targetConfig, _ := conf.TargetFor(target)
+
 		var authModule config.AuthModule
 		authModuleName := params.Get("auth_module")
+		if authModuleName == "" {
+			authModuleName = targetConfig.AuthModule
+		}
 		if authModuleName == "" {
 			level.Info(logger).Log("msg", "no auth_module specified, using default")
 		} else {
@@ -52,18 +58,23 @@ func handleProbe(logger log.Logger, excludeDatabases []string) http.HandlerFunc
 			}
 		}
 
-		dsn, err := authModule.ConfigureTarget(target)
+		dsn, err := targetConfig.ConfigureTarget(authModule, target)
 		if err != nil {
 			level.Error(logger).Log("msg", "failed to configure target", "err", err)
 			http.Error(w, fmt.Sprintf("could not configure dsn for target: %v", err), http.StatusBadRequest)
 			return
 		}
 
-		// TODO(@sysadmind): Timeout
+		if targetConfig.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, targetConfig.Timeout)
+			defer cancel()
+		}
 
 		tl := log.With(logger, "target", target)
 
 		registry := prometheus.NewRegistry()
+		registerer := prometheus.WrapRegistererWith(targetConfig.Labels, registry)
 
 		opts := []ExporterOpt{
 			DisableDefaultMetrics(*disableDefaultMetrics),
@@ -80,10 +91,10 @@ func handleProbe(logger log.Logger, excludeDatabases []string) http.HandlerFunc
 		defer func() {
 			exporter.servers.Close()
 		}()
-		registry.MustRegister(exporter)
+		registerer.MustRegister(exporter)
 
 		// Run the probe
-		pc, err := collector.NewProbeCollector(tl, excludeDatabases, registry, dsn)
+		pc, err := collector.NewProbeCollector(ctx, tl, excludeDatabases, registry, dsn, targetConfig.Collectors)
 		if err != nil {
 			level.Error(logger).Log("msg", "Error creating probe collector", "err", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -94,15 +105,25 @@ func handleProbe(logger log.Logger, excludeDatabases []string) http.HandlerFunc
 		defer pc.Close()
 
 		// TODO(@sysadmind): Remove the registry.MustRegister() call below and instead handle the collection here. That will allow
-		// for the passing of context, handling of timeouts, and more control over the collection.
+		// for more control over the collection.
 		// The current NewProbeCollector() implementation relies on the MustNewConstMetric() call to create the metrics which is not
 		// ideal to use without the registry.MustRegister() call.
-		_ = ctx
-
-		registry.MustRegister(pc)
+		registerer.MustRegister(pc)
 
 		// TODO check success, etc
 		h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 		h.ServeHTTP(w, r)
 	}
 }
+
+// validateTargetCollectors checks that the collectors listed for every probe
+// target exist and are enabled, so that a config naming an unknown collector
+// is rejected on load instead of failing every probe of that target.
+func validateTargetCollectors(conf *config.Config) error {
+	for _, t := range conf.Targets {
+		if err := collector.ValidateCollectorFilters(t.Collectors); err != nil {
+			return fmt.Errorf("target %q: %w", t.Pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus-community/postgres_exporter/config"
+)
+
+func TestHandleProbeRejectsBadRequests(t *testing.T) {
+	previous := c.GetConfig()
+	c.Config = &config.Config{
+		AuthModules: map[string]config.AuthModule{
+			"no_password": {
+				Type:     "userpass",
+				UserPass: config.UserPass{Username: "exporter"},
+			},
+		},
+	}
+	defer func() { c.Config = previous }()
+
+	for _, tc := range []struct {
+		name  string
+		query string
+	}{
+		{"missing target", ""},
+		{"unknown auth module", "?target=db1:5432&auth_module=missing"},
+		{"auth module without password", "?target=db1:5432&auth_module=no_password"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/probe"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			handleProbe(log.NewNopLogger(), nil)(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("handleProbe(%q) returned status %d, want %d", tc.query, rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -15,15 +15,19 @@ package collector
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 var (
@@ -42,6 +46,21 @@ const (
 	defaultDisabled = false
 )
 
+// deprecatedStatsResetMetrics keeps exporting the stats_reset timestamps
+// under the names they had before they became *_stats_reset_timestamp_seconds
+// gauges. They were counters, which breaks rate() when statistics are reset.
+var deprecatedStatsResetMetrics = kingpin.Flag("collector.deprecated-stats-reset-metrics", "Also export the stats_reset timestamps as the counters they were exported as before (DEPRECATED, will be removed in the next release).").Default("true").Bool()
+
+var (
+	collectorTimeout          = kingpin.Flag("collector.timeout", "Maximum duration of a single collector run. Disabled when 0.").Default("0s").Duration()
+	collectorTimeoutOverrides = kingpin.Flag("collector.timeout-override", "Maximum duration of a run of the given collector as <collector>=<duration>, overriding --collector.timeout. Can be repeated.").StringMap()
+)
+
+var (
+	collectorIncludeDatabases = kingpin.Flag("collector.include-databases", "Comma-separated list of databases to report in the collectors that label metrics by datname. All databases are reported when empty.").Default("").String()
+	collectorExcludeDatabases = kingpin.Flag("collector.exclude-databases", "Comma-separated list of databases to leave out of the collectors that label metrics by datname, in addition to --exclude-databases.").Default("").String()
+)
+
 var (
 	scrapeDurationDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "scrape", "collector_duration_seconds"),
@@ -49,12 +68,41 @@ var (
 		[]string{"collector"},
 		nil,
 	)
+	scrapeTimeoutDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "scrape", "collector_timeout"),
+		"postgres_exporter: Whether a collector was canceled because it ran past its timeout.",
+		[]string{"collector"},
+		nil,
+	)
 	scrapeSuccessDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "scrape", "collector_success"),
 		"postgres_exporter: Whether a collector succeeded.",
 		[]string{"collector"},
 		nil,
 	)
+
+	// scrapesTotal, scrapeErrorsTotal and connectDuration are registered once
+	// with the default registry, so they keep counting across config reloads
+	// and also cover /probe.
+	scrapesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "exporter",
+		Name:      "scrapes_total",
+		Help:      "postgres_exporter: Total number of times a collector was run.",
+	}, []string{"collector"})
+	scrapeErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "exporter",
+		Name:      "scrape_errors_total",
+		Help:      "postgres_exporter: Total number of times a collector failed, not counting runs which returned no data.",
+	}, []string{"collector"})
+	connectDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "connection",
+		Name:      "establish_seconds",
+		Help:      "postgres_exporter: Time taken to establish a new connection to the database, including TLS handshake and authentication.",
+		Buckets:   prometheus.DefBuckets,
+	})
 )
 
 type Collector interface {
@@ -62,8 +110,126 @@ type Collector interface {
 }
 
 type collectorConfig struct {
-	logger           log.Logger
+	logger log.Logger
+	// excludeDatabases and includeDatabases select the databases reported
+	// by the collectors that label metrics by datname, see databaseFilter.
 	excludeDatabases []string
+	includeDatabases []string
+}
+
+// newCollectorConfig returns the config of the named collector. The
+// databases excluded on the command line are added to excludeDatabases.
+func newCollectorConfig(logger log.Logger, name string, excludeDatabases []string) collectorConfig {
+	exclude := append([]string{}, excludeDatabases...)
+	exclude = append(exclude, splitList(*collectorExcludeDatabases)...)
+	return collectorConfig{
+		logger:           log.With(logger, "collector", name),
+		excludeDatabases: exclude,
+		includeDatabases: splitList(*collectorIncludeDatabases),
+	}
+}
+
+const currentDatabaseQuery = "SELECT current_database()"
+
+// databaseFilter selects the databases reported by the collectors that label
+// their metrics by datname. It is configured through --exclude-databases and
+// the --collector.include-databases and --collector.exclude-databases flags.
+type databaseFilter struct {
+	include []string
+	exclude []string
+}
+
+func newDatabaseFilter(include, exclude []string) databaseFilter {
+	return databaseFilter{
+		include: nonEmpty(include),
+		exclude: nonEmpty(exclude),
+	}
+}
+
+// allowed reports whether datname should be reported. An empty include list
+// allows every database that is not excluded. Rows without a database, such
+// as background processes in pg_stat_activity, have an empty datname and are
+// always allowed.
+func (f databaseFilter) allowed(datname string) bool {
+	if datname == "" {
+		return true
+	}
+	if sliceContains(f.exclude, datname) {
+		return false
+	}
+	return len(f.include) == 0 || sliceContains(f.include, datname)
+}
+
+// allowedCurrent reports whether the database db is connected to is
+// allowed. The collectors that only report current_database() call it once
+// before running their queries, so an excluded database is not queried at
+// all. No query is run when the filter allows every database.
+func (f databaseFilter) allowedCurrent(ctx context.Context, db *sql.DB) (bool, error) {
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return true, nil
+	}
+	var datname string
+	if err := db.QueryRowContext(ctx, currentDatabaseQuery).Scan(&datname); err != nil {
+		return false, err
+	}
+	return f.allowed(datname), nil
+}
+
+// where returns a WHERE clause on the datname column implementing the
+// filter, along with its query arguments. Filtering on the server saves it
+// from computing statistics for databases that are dropped anyway. Both are
+// empty when the filter allows every database.
+func (f databaseFilter) where() (string, []any) {
+	var conditions []string
+	var args []any
+	if len(f.include) > 0 {
+		args = append(args, pq.Array(f.include))
+		conditions = append(conditions, fmt.Sprintf("datname = ANY($%d)", len(args)))
+	}
+	if len(f.exclude) > 0 {
+		args = append(args, pq.Array(f.exclude))
+		conditions = append(conditions, fmt.Sprintf("NOT datname = ANY($%d)", len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// and returns the conditions implementing the filter on column, to be added
+// to an existing WHERE clause, along with args extended by their query
+// arguments. Unlike where, rows without a database are kept like in allowed,
+// so the filter can be applied before an ORDER BY ... LIMIT picks the rows.
+func (f databaseFilter) and(column string, args []any) (string, []any) {
+	var conditions []string
+	if len(f.include) > 0 {
+		args = append(args, pq.Array(f.include))
+		conditions = append(conditions, fmt.Sprintf("(%s IS NULL OR %s = ANY($%d))", column, column, len(args)))
+	}
+	if len(f.exclude) > 0 {
+		args = append(args, pq.Array(f.exclude))
+		conditions = append(conditions, fmt.Sprintf("(%s IS NULL OR NOT %s = ANY($%d))", column, column, len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " AND " + strings.Join(conditions, " AND "), args
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(list string) []string {
+	return nonEmpty(strings.Split(list, ","))
+}
+
+// nonEmpty returns the trimmed entries of list that are not empty.
+func nonEmpty(list []string) []string {
+	var items []string
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func registerCollector(name string, isDefaultEnabled bool, createFunc func(collectorConfig) (Collector, error)) {
@@ -92,6 +258,7 @@ type PostgresCollector struct {
 	logger     log.Logger
 
 	instance *instance
+	timeouts collectorTimeouts
 }
 
 type Option func(*PostgresCollector) error
@@ -109,16 +276,13 @@ func NewPostgresCollector(logger log.Logger, excludeDatabases []string, dsn stri
 		}
 	}
 
-	f := make(map[string]bool)
-	for _, filter := range filters {
-		enabled, exist := collectorState[filter]
-		if !exist {
-			return nil, fmt.Errorf("missing collector: %s", filter)
-		}
-		if !*enabled {
-			return nil, fmt.Errorf("disabled collector: %s", filter)
-		}
-		f[filter] = true
+	f, err := collectorFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	p.timeouts, err = newCollectorTimeouts(*collectorTimeout, *collectorTimeoutOverrides)
+	if err != nil {
+		return nil, err
 	}
 	collectors := make(map[string]Collector)
 	initiatedCollectorsMtx.Lock()
@@ -130,10 +294,7 @@ func NewPostgresCollector(logger log.Logger, excludeDatabases []string, dsn stri
 		if collector, ok := initiatedCollectors[key]; ok {
 			collectors[key] = collector
 		} else {
-			collector, err := factories[key](collectorConfig{
-				logger:           log.With(logger, "collector", key),
-				excludeDatabases: excludeDatabases,
-			})
+			collector, err := factories[key](newCollectorConfig(logger, key, excludeDatabases))
 			if err != nil {
 				return nil, err
 			}
@@ -144,6 +305,8 @@ func NewPostgresCollector(logger log.Logger, excludeDatabases []string, dsn stri
 
 	p.Collectors = collectors
 
+	initScrapeCounters(collectors)
+
 	if dsn == "" {
 		return nil, errors.New("empty dsn")
 	}
@@ -157,10 +320,34 @@ func NewPostgresCollector(logger log.Logger, excludeDatabases []string, dsn stri
 	return p, nil
 }
 
+// ValidateCollectorFilters returns an error if any of filters does not name
+// an enabled collector.
+func ValidateCollectorFilters(filters []string) error {
+	_, err := collectorFilters(filters)
+	return err
+}
+
+// collectorFilters validates the requested collector names and returns them as a set.
+func collectorFilters(filters []string) (map[string]bool, error) {
+	f := make(map[string]bool)
+	for _, filter := range filters {
+		enabled, exist := collectorState[filter]
+		if !exist {
+			return nil, fmt.Errorf("missing collector: %s", filter)
+		}
+		if !*enabled {
+			return nil, fmt.Errorf("disabled collector: %s", filter)
+		}
+		f[filter] = true
+	}
+	return f, nil
+}
+
 // Describe implements the prometheus.Collector interface.
 func (p PostgresCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- scrapeDurationDesc
 	ch <- scrapeSuccessDesc
+	ch <- scrapeTimeoutDesc
 }
 
 // Collect implements the prometheus.Collector interface.
@@ -177,19 +364,81 @@ func (p PostgresCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 	defer inst.Close()
+	connectDuration.Observe(inst.connectDuration.Seconds())
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(p.Collectors))
 	for name, c := range p.Collectors {
 		go func(name string, c Collector) {
-			execute(ctx, name, c, inst, ch, p.logger)
+			err := execute(ctx, name, c, inst, ch, p.logger, p.timeouts.get(name))
+			recordScrape(name, err)
 			wg.Done()
 		}(name, c)
 	}
 	wg.Wait()
 }
 
-func execute(ctx context.Context, name string, c Collector, instance *instance, ch chan<- prometheus.Metric, logger log.Logger) {
+// initScrapeCounters exports the scrape counters of collectors before their
+// first run.
+func initScrapeCounters(collectors map[string]Collector) {
+	for name := range collectors {
+		scrapesTotal.WithLabelValues(name)
+		scrapeErrorsTotal.WithLabelValues(name)
+	}
+}
+
+// recordScrape counts a run of the named collector and whether it failed.
+func recordScrape(name string, err error) {
+	scrapesTotal.WithLabelValues(name).Inc()
+	if err != nil && !IsNoDataError(err) {
+		scrapeErrorsTotal.WithLabelValues(name).Inc()
+	}
+}
+
+// collectorTimeouts holds the maximum duration of a run of each collector.
+type collectorTimeouts struct {
+	fallback  time.Duration
+	overrides map[string]time.Duration
+}
+
+func newCollectorTimeouts(fallback time.Duration, overrides map[string]string) (collectorTimeouts, error) {
+	t := collectorTimeouts{
+		fallback:  fallback,
+		overrides: make(map[string]time.Duration, len(overrides)),
+	}
+	for name, value := range overrides {
+		if _, ok := factories[name]; !ok {
+			return t, fmt.Errorf("timeout override for missing collector: %s", name)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return t, fmt.Errorf("invalid timeout override for collector %s: %w", name, err)
+		}
+		t.overrides[name] = d
+	}
+	return t, nil
+}
+
+// get returns the timeout of the named collector, 0 meaning no timeout.
+func (t collectorTimeouts) get(name string) time.Duration {
+	if d, ok := t.overrides[name]; ok {
+		return d
+	}
+	return t.fallback
+}
+
+// execute runs the collector, emits its scrape duration and success metrics
+// and returns the error of the collector, if any. When timeout is positive
+// the collector runs under a context canceled after timeout, and whether that
+// happened is reported as well, so a slow collector fails on its own instead
+// of holding up the whole scrape.
+func execute(ctx context.Context, name string, c Collector, instance *instance, ch chan<- prometheus.Metric, logger log.Logger, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	begin := time.Now()
 	err := c.Update(ctx, instance, ch)
 	duration := time.Since(begin)
@@ -208,6 +457,14 @@ func execute(ctx context.Context, name string, c Collector, instance *instance,
 	}
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration.Seconds(), name)
 	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, name)
+	if timeout > 0 {
+		timedOut := 0.0
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			timedOut = 1
+		}
+		ch <- prometheus.MustNewConstMetric(scrapeTimeoutDesc, prometheus.GaugeValue, timedOut, name)
+	}
+	return err
 }
 
 // collectorFlagAction generates a new action function for the given collector
@@ -228,3 +485,9 @@ var ErrNoData = errors.New("collector returned no data")
 func IsNoDataError(err error) bool {
 	return err == ErrNoData
 }
+
+// timeToEpochSeconds converts t to seconds since the Unix epoch, keeping
+// sub-second precision.
+func timeToEpochSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
@@ -13,8 +13,14 @@
 package collector
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"strings"
+	"testing"
+	"time"
 
+	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 )
@@ -60,3 +66,207 @@ func sanitizeQuery(q string) string {
 	q = strings.Replace(q, "$", "\\$", -1)
 	return q
 }
+
+func TestTimeToEpochSeconds(t *testing.T) {
+	ts := time.Date(2023, time.May, 25, 17, 10, 42, 500000000, time.UTC)
+	if got, want := timeToEpochSeconds(ts), 1685034642.5; got != want {
+		t.Errorf("timeToEpochSeconds(%s) = %f, want %f", ts, got, want)
+	}
+}
+
+type fakeCollector struct {
+	err error
+}
+
+func (c fakeCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	return c.err
+}
+
+func TestScrapeCounters(t *testing.T) {
+	collectors := map[string]Collector{
+		"ok":      fakeCollector{},
+		"failing": fakeCollector{err: errors.New("boom")},
+		"empty":   fakeCollector{err: ErrNoData},
+	}
+	initScrapeCounters(collectors)
+	scrapes := map[string]float64{}
+	scrapeErrors := map[string]float64{}
+	for name := range collectors {
+		scrapes[name] = readMetric(scrapesTotal.WithLabelValues(name)).value
+		scrapeErrors[name] = readMetric(scrapeErrorsTotal.WithLabelValues(name)).value
+	}
+
+	ch := make(chan prometheus.Metric, 2)
+	for i := 0; i < 2; i++ {
+		for name, c := range collectors {
+			recordScrape(name, execute(context.Background(), name, c, &instance{}, ch, log.NewNopLogger(), 0))
+			<-ch
+			<-ch
+		}
+	}
+
+	for name, want := range map[string]float64{"ok": 0, "failing": 2, "empty": 0} {
+		if got := readMetric(scrapesTotal.WithLabelValues(name)).value - scrapes[name]; got != 2 {
+			t.Errorf("scrapes_total{collector=%q} increased by %f, want 2", name, got)
+		}
+		if got := readMetric(scrapeErrorsTotal.WithLabelValues(name)).value - scrapeErrors[name]; got != want {
+			t.Errorf("scrape_errors_total{collector=%q} increased by %f, want %f", name, got, want)
+		}
+	}
+}
+
+// blockingCollector waits until its context is canceled.
+type blockingCollector struct{}
+
+func (blockingCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	for name, tc := range map[string]struct {
+		c           Collector
+		wantSuccess float64
+		wantTimeout float64
+	}{
+		"slow": {blockingCollector{}, 0, 1},
+		"fast": {fakeCollector{}, 1, 0},
+	} {
+		ch := make(chan prometheus.Metric, 3)
+		err := execute(context.Background(), name, tc.c, &instance{}, ch, log.NewNopLogger(), 10*time.Millisecond)
+		if tc.wantTimeout == 1 && !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("execute(%s) returned %v, want %v", name, err, context.DeadlineExceeded)
+		}
+		<-ch
+		if got := readMetric(<-ch).value; got != tc.wantSuccess {
+			t.Errorf("collector_success{collector=%q} = %f, want %f", name, got, tc.wantSuccess)
+		}
+		if got := readMetric(<-ch).value; got != tc.wantTimeout {
+			t.Errorf("collector_timeout{collector=%q} = %f, want %f", name, got, tc.wantTimeout)
+		}
+	}
+}
+
+func TestNewCollectorTimeouts(t *testing.T) {
+	timeouts, err := newCollectorTimeouts(5*time.Second, map[string]string{"stat_statements": "30s"})
+	if err != nil {
+		t.Fatalf("Error parsing collector timeouts: %s", err)
+	}
+	if got := timeouts.get("stat_statements"); got != 30*time.Second {
+		t.Errorf("timeout of stat_statements = %s, want 30s", got)
+	}
+	if got := timeouts.get("database"); got != 5*time.Second {
+		t.Errorf("timeout of database = %s, want 5s", got)
+	}
+
+	for _, overrides := range []map[string]string{
+		{"no_such_collector": "30s"},
+		{"stat_statements": "soon"},
+	} {
+		if _, err := newCollectorTimeouts(0, overrides); err == nil {
+			t.Errorf("newCollectorTimeouts(0, %v) succeeded, want an error", overrides)
+		}
+	}
+}
+
+func TestExecuteScrapeMetrics(t *testing.T) {
+	for name, tc := range map[string]struct {
+		c           Collector
+		wantSuccess float64
+	}{
+		"ok":      {fakeCollector{}, 1},
+		"failing": {fakeCollector{err: errors.New("boom")}, 0},
+		"empty":   {fakeCollector{err: ErrNoData}, 0},
+	} {
+		ch := make(chan prometheus.Metric, 2)
+		execute(context.Background(), name, tc.c, &instance{}, ch, log.NewNopLogger(), 0)
+		close(ch)
+
+		duration := <-ch
+		if got := duration.Desc(); got != scrapeDurationDesc {
+			t.Errorf("first metric of %s is %s, want %s", name, got, scrapeDurationDesc)
+		}
+		if got := readMetric(duration); got.labels["collector"] != name || got.value < 0 {
+			t.Errorf("collector_duration_seconds of %s = %+v", name, got)
+		}
+
+		success := <-ch
+		if got := success.Desc(); got != scrapeSuccessDesc {
+			t.Errorf("second metric of %s is %s, want %s", name, got, scrapeSuccessDesc)
+		}
+		if got := readMetric(success); got.labels["collector"] != name || got.value != tc.wantSuccess {
+			t.Errorf("collector_success of %s = %+v, want value %f", name, got, tc.wantSuccess)
+		}
+
+		if _, ok := <-ch; ok {
+			t.Errorf("execute(%s) without a timeout emitted more than the duration and success metrics", name)
+		}
+	}
+}
+
+func TestDatabaseFilter(t *testing.T) {
+	for _, tc := range []struct {
+		include, exclude string
+		datname          string
+		want             bool
+		where            string
+	}{
+		{"", "", "postgres", true, ""},
+		{"", "tenant_1", "postgres", true, " WHERE NOT datname = ANY($1)"},
+		{"", "tenant_1, tenant_2", "tenant_2", false, " WHERE NOT datname = ANY($1)"},
+		{"app,billing", "", "billing", true, " WHERE datname = ANY($1)"},
+		{"app,billing", "", "postgres", false, " WHERE datname = ANY($1)"},
+		{"app", "app", "app", false, " WHERE datname = ANY($1) AND NOT datname = ANY($2)"},
+		{"app", "", "", true, " WHERE datname = ANY($1)"},
+		{",", " , ", "postgres", true, ""},
+	} {
+		f := newDatabaseFilter(splitList(tc.include), splitList(tc.exclude))
+		if got := f.allowed(tc.datname); got != tc.want {
+			t.Errorf("newDatabaseFilter(%q, %q).allowed(%q) = %t, want %t", tc.include, tc.exclude, tc.datname, got, tc.want)
+		}
+		if where, _ := f.where(); where != tc.where {
+			t.Errorf("newDatabaseFilter(%q, %q).where() = %q, want %q", tc.include, tc.exclude, where, tc.where)
+		}
+	}
+}
+
+func TestDatabaseFilterAnd(t *testing.T) {
+	for _, tc := range []struct {
+		include, exclude string
+		want             string
+		args             int
+	}{
+		{"", "", "", 1},
+		{"", "tenant_1", " AND (d.datname IS NULL OR NOT d.datname = ANY($2))", 2},
+		{"app", "", " AND (d.datname IS NULL OR d.datname = ANY($2))", 2},
+		{"app", "tenant_1", " AND (d.datname IS NULL OR d.datname = ANY($2)) AND (d.datname IS NULL OR NOT d.datname = ANY($3))", 3},
+	} {
+		f := newDatabaseFilter(splitList(tc.include), splitList(tc.exclude))
+		got, args := f.and("d.datname", []any{10})
+		if got != tc.want {
+			t.Errorf("newDatabaseFilter(%q, %q).and() = %q, want %q", tc.include, tc.exclude, got, tc.want)
+		}
+		if len(args) != tc.args {
+			t.Errorf("newDatabaseFilter(%q, %q).and() returned %d args, want %d", tc.include, tc.exclude, len(args), tc.args)
+		}
+	}
+}
+
+func TestNewCollectorConfigDatabases(t *testing.T) {
+	include, exclude := *collectorIncludeDatabases, *collectorExcludeDatabases
+	defer func() {
+		*collectorIncludeDatabases, *collectorExcludeDatabases = include, exclude
+	}()
+	*collectorIncludeDatabases = "app, billing"
+	*collectorExcludeDatabases = "tenant_2"
+
+	config := newCollectorConfig(log.NewNopLogger(), "locks", []string{"tenant_1", ""})
+	f := newDatabaseFilter(config.includeDatabases, config.excludeDatabases)
+
+	if !reflect.DeepEqual(f.include, []string{"app", "billing"}) {
+		t.Errorf("include = %q, want [app billing]", f.include)
+	}
+	if !reflect.DeepEqual(f.exclude, []string{"tenant_1", "tenant_2"}) {
+		t.Errorf("exclude = %q, want [tenant_1 tenant_2]", f.exclude)
+	}
+}
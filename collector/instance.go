@@ -14,9 +14,11 @@
 package collector
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/blang/semver/v4"
 )
@@ -25,6 +27,10 @@ type instance struct {
 	dsn     string
 	db      *sql.DB
 	version semver.Version
+
+	// connectDuration is how long setup took to establish the connection,
+	// including the TLS handshake and authentication.
+	connectDuration time.Duration
 }
 
 func newInstance(dsn string) (*instance, error) {
@@ -59,6 +65,17 @@ func (i *instance) setup() error {
 	db.SetMaxIdleConns(1)
 	i.db = db
 
+	// sql.Open does not connect, so take a connection from the pool here to
+	// time the connection setup on its own. It is handed back to the pool
+	// and reused by the queries below.
+	begin := time.Now()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("error connecting to postgresql: %w", err)
+	}
+	i.connectDuration = time.Since(begin)
+	conn.Close()
+
 	version, err := queryVersion(i.db)
 	if err != nil {
 		return fmt.Errorf("error querying postgresql version: %w", err)
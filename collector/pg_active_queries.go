@@ -0,0 +1,115 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const activeQueriesSubsystem = "active_queries"
+
+func init() {
+	// Creates one series per normalized query, so it is disabled by default
+	// and capped by --collector.active_queries.limit.
+	registerCollector(activeQueriesSubsystem, defaultDisabled, NewPGActiveQueriesCollector)
+}
+
+var activeQueriesLimit = kingpin.Flag("collector.active_queries.limit", "Maximum number of query IDs to report, most concurrently executing first.").Default("10").Int()
+
+type PGActiveQueriesCollector struct {
+	log       log.Logger
+	databases databaseFilter
+	limit     int
+}
+
+func NewPGActiveQueriesCollector(config collectorConfig) (Collector, error) {
+	return &PGActiveQueriesCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		limit:     *activeQueriesLimit,
+	}, nil
+}
+
+var (
+	activeQueriesByID = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, activeQueriesSubsystem, "by_id"),
+		"Number of backends currently executing the normalized query with this query_id",
+		[]string{"datname", "query_id"},
+		prometheus.Labels{},
+	)
+
+	// query_id is NULL for every backend when compute_query_id is off, in
+	// which case nothing is reported.
+	activeQueriesByIDQuery = `
+	SELECT
+		datname,
+		query_id::text AS query_id,
+		COUNT(*) AS backends
+	FROM pg_catalog.pg_stat_activity
+	WHERE state = 'active'
+		AND query_id IS NOT NULL
+		AND pid <> pg_backend_pid()%s
+	GROUP BY datname, query_id
+	ORDER BY backends DESC
+	LIMIT $1
+	`
+)
+
+func (c *PGActiveQueriesCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// query_id was added to pg_stat_activity in PostgreSQL 14
+	if instance.version.LT(semver.MustParse("14.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_stat_activity.query_id is not available before PostgreSQL 14")
+		return nil
+	}
+
+	db := instance.getDB()
+	databases, args := c.databases.and("datname", []any{c.limit})
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(activeQueriesByIDQuery, databases), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, queryID sql.NullString
+		var backends sql.NullInt64
+
+		if err := rows.Scan(&datname, &queryID, &backends); err != nil {
+			return err
+		}
+		if !queryID.Valid {
+			continue
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		ch <- prometheus.MustNewConstMetric(
+			activeQueriesByID,
+			prometheus.GaugeValue,
+			float64(backends.Int64),
+			datnameLabel, queryID.String,
+		)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const applicationVersionSubsystem = "application_version"
+
+func init() {
+	registerCollector(applicationVersionSubsystem, defaultDisabled, NewPGApplicationVersionCollector)
+}
+
+var applicationVersionRegex = kingpin.Flag("collector.application_version.regex", "Regular expression applied to application_name. The first capture group is used as the version label.").Default(`([0-9]+(?:\.[0-9]+)+)`).String()
+
+type PGApplicationVersionCollector struct {
+	log   log.Logger
+	regex *regexp.Regexp
+}
+
+func NewPGApplicationVersionCollector(config collectorConfig) (Collector, error) {
+	regex, err := regexp.Compile(*applicationVersionRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --collector.application_version.regex: %w", err)
+	}
+	if regex.NumSubexp() < 1 {
+		return nil, fmt.Errorf("--collector.application_version.regex %q has no capture group", *applicationVersionRegex)
+	}
+	return &PGApplicationVersionCollector{
+		log:   config.logger,
+		regex: regex,
+	}, nil
+}
+
+var (
+	applicationVersionConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, applicationVersionSubsystem, "connections"),
+		"Number of client connections by the version extracted from application_name",
+		[]string{"version"},
+		prometheus.Labels{},
+	)
+
+	applicationVersionQuery = `
+	SELECT
+		application_name,
+		COUNT(*) AS connections
+	FROM pg_catalog.pg_stat_activity
+	WHERE state IS NOT NULL
+	GROUP BY application_name
+	`
+)
+
+func (c *PGApplicationVersionCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		applicationVersionQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Several application names can map to the same version, so the
+	// counts are summed before emitting.
+	var versions []string
+	connectionsByVersion := make(map[string]float64)
+	for rows.Next() {
+		var applicationName sql.NullString
+		var connections sql.NullInt64
+
+		if err := rows.Scan(&applicationName, &connections); err != nil {
+			return err
+		}
+
+		version := "unknown"
+		if applicationName.Valid {
+			if m := c.regex.FindStringSubmatch(applicationName.String); m != nil && m[1] != "" {
+				version = m[1]
+			}
+		}
+		if _, ok := connectionsByVersion[version]; !ok {
+			versions = append(versions, version)
+		}
+		if connections.Valid {
+			connectionsByVersion[version] += float64(connections.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		ch <- prometheus.MustNewConstMetric(
+			applicationVersionConnections,
+			prometheus.GaugeValue,
+			connectionsByVersion[version],
+			version,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGApplicationVersionCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	rows := sqlmock.NewRows([]string{"application_name", "connections"}).
+		AddRow("orders/pgx-5.4.3", 10).
+		AddRow("psql", 1).
+		AddRow("billing/pgx-5.4.3", 5).
+		AddRow("reports/pgx-4.18.1", 2).
+		AddRow(nil, 3)
+	mock.ExpectQuery(sanitizeQuery(applicationVersionQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGApplicationVersionCollector{
+			regex: regexp.MustCompile(`pgx-([0-9.]+)`),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGApplicationVersionCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"version": "5.4.3"}, metricType: dto.MetricType_GAUGE, value: 15},
+		{labels: labelMap{"version": "unknown"}, metricType: dto.MetricType_GAUGE, value: 4},
+		{labels: labelMap{"version": "4.18.1"}, metricType: dto.MetricType_GAUGE, value: 2},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestNewPGApplicationVersionCollectorInvalidRegex(t *testing.T) {
+	defer func(regex string) { *applicationVersionRegex = regex }(*applicationVersionRegex)
+
+	for _, regex := range []string{`pgx-(`, `pgx-[0-9.]+`} {
+		*applicationVersionRegex = regex
+		if _, err := NewPGApplicationVersionCollector(collectorConfig{}); err == nil {
+			t.Errorf("expected an error for regex %q", regex)
+		}
+	}
+}
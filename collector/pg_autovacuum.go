@@ -0,0 +1,126 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const autovacuumSubsystem = "autovacuum"
+
+func init() {
+	registerCollector(autovacuumSubsystem, defaultDisabled, NewPGAutovacuumCollector)
+}
+
+// PGAutovacuumCollector pairs the autovacuum settings with whether the
+// autovacuum launcher is actually running. A launcher that is down while
+// autovacuum is on means no table is vacuumed or analyzed automatically.
+// Standbys never run the launcher, so launcher_up is only reported on
+// primaries.
+type PGAutovacuumCollector struct {
+	log log.Logger
+}
+
+func NewPGAutovacuumCollector(config collectorConfig) (Collector, error) {
+	return &PGAutovacuumCollector{log: config.logger}, nil
+}
+
+var (
+	autovacuumEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, autovacuumSubsystem, "enabled"),
+		"Whether the autovacuum setting is on (1 = on, 0 = off)",
+		[]string{},
+		prometheus.Labels{},
+	)
+	autovacuumNaptime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, autovacuumSubsystem, "naptime_seconds"),
+		"Minimum delay between autovacuum runs on any given database",
+		[]string{},
+		prometheus.Labels{},
+	)
+	autovacuumLauncherUp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, autovacuumSubsystem, "launcher_up"),
+		"Whether the autovacuum launcher process is running (1 = running, 0 = not running). Not reported on standbys, which do not run the launcher",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	autovacuumQuery = `
+	SELECT
+		current_setting('autovacuum')::bool AS enabled,
+		EXTRACT(EPOCH FROM current_setting('autovacuum_naptime')::interval) AS naptime_seconds,
+		EXISTS (
+			SELECT 1 FROM pg_catalog.pg_stat_activity
+			WHERE backend_type = 'autovacuum launcher'
+		) AS launcher_up,
+		pg_is_in_recovery() AS in_recovery
+	`
+
+	// pg_stat_activity has no backend_type before PostgreSQL 10.
+	autovacuumQueryPre10 = `
+	SELECT
+		current_setting('autovacuum')::bool AS enabled,
+		EXTRACT(EPOCH FROM current_setting('autovacuum_naptime')::interval) AS naptime_seconds,
+		NULL::bool AS launcher_up,
+		pg_is_in_recovery() AS in_recovery
+	`
+)
+
+func (c *PGAutovacuumCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	query := autovacuumQuery
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		query = autovacuumQueryPre10
+	}
+
+	db := instance.getDB()
+	var enabled, launcherUp, inRecovery sql.NullBool
+	var naptime sql.NullFloat64
+	if err := db.QueryRowContext(ctx, query).Scan(&enabled, &naptime, &launcherUp, &inRecovery); err != nil {
+		return err
+	}
+
+	enabledMetric := 0.0
+	if enabled.Bool {
+		enabledMetric = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(
+		autovacuumEnabled,
+		prometheus.GaugeValue,
+		enabledMetric,
+	)
+	if naptime.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			autovacuumNaptime,
+			prometheus.GaugeValue,
+			naptime.Float64,
+		)
+	}
+	if launcherUp.Valid && !inRecovery.Bool {
+		launcherUpMetric := 0.0
+		if launcherUp.Bool {
+			launcherUpMetric = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(
+			autovacuumLauncherUp,
+			prometheus.GaugeValue,
+			launcherUpMetric,
+		)
+	}
+	return nil
+}
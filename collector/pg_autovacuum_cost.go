@@ -0,0 +1,109 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const autovacuumCostSubsystem = "autovacuum_cost"
+
+func init() {
+	registerCollector(autovacuumCostSubsystem, defaultDisabled, NewPGAutovacuumCostCollector)
+}
+
+type PGAutovacuumCostCollector struct {
+	log log.Logger
+}
+
+func NewPGAutovacuumCostCollector(config collectorConfig) (Collector, error) {
+	return &PGAutovacuumCostCollector{log: config.logger}, nil
+}
+
+var (
+	autovacuumCostLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, autovacuumCostSubsystem, "limit"),
+		"Cost limit shared by all autovacuum workers: autovacuum_vacuum_cost_limit, or vacuum_cost_limit if it is -1",
+		[]string{},
+		prometheus.Labels{},
+	)
+	autovacuumCostVacuumCostLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, autovacuumCostSubsystem, "vacuum_cost_limit"),
+		"Value of the vacuum_cost_limit setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+	autovacuumCostMaxWorkers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, autovacuumCostSubsystem, "max_workers"),
+		"Value of the autovacuum_max_workers setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+	autovacuumCostLimitPerWorker = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, autovacuumCostSubsystem, "limit_per_worker"),
+		"Cost limit each autovacuum worker gets when all autovacuum_max_workers are running",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	autovacuumCostQuery = `
+	SELECT
+		current_setting('autovacuum_vacuum_cost_limit')::int AS autovacuum_vacuum_cost_limit,
+		current_setting('vacuum_cost_limit')::int AS vacuum_cost_limit,
+		current_setting('autovacuum_max_workers')::int AS autovacuum_max_workers
+	`
+)
+
+func (c *PGAutovacuumCostCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	var autovacuumLimit, vacuumLimit, maxWorkers sql.NullInt64
+	if err := db.QueryRowContext(ctx, autovacuumCostQuery).Scan(&autovacuumLimit, &vacuumLimit, &maxWorkers); err != nil {
+		return err
+	}
+	if !autovacuumLimit.Valid || !vacuumLimit.Valid || !maxWorkers.Valid {
+		return ErrNoData
+	}
+
+	limit := float64(autovacuumLimit.Int64)
+	if autovacuumLimit.Int64 == -1 {
+		limit = float64(vacuumLimit.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		autovacuumCostLimit,
+		prometheus.GaugeValue,
+		limit,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		autovacuumCostVacuumCostLimit,
+		prometheus.GaugeValue,
+		float64(vacuumLimit.Int64),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		autovacuumCostMaxWorkers,
+		prometheus.GaugeValue,
+		float64(maxWorkers.Int64),
+	)
+	if maxWorkers.Int64 > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			autovacuumCostLimitPerWorker,
+			prometheus.GaugeValue,
+			limit/float64(maxWorkers.Int64),
+		)
+	}
+	return nil
+}
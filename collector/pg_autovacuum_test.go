@@ -0,0 +1,116 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGAutovacuumCollector(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		query    string
+		row      []driver.Value
+		expected []MetricResult
+	}{
+		{
+			name:    "launcher running",
+			version: "16.0.0",
+			query:   autovacuumQuery,
+			row:     []driver.Value{true, 60.0, true, false},
+			expected: []MetricResult{
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1},
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 60},
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1},
+			},
+		},
+		{
+			name:    "launcher missing",
+			version: "16.0.0",
+			query:   autovacuumQuery,
+			row:     []driver.Value{true, 15.0, false, false},
+			expected: []MetricResult{
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1},
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 15},
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 0},
+			},
+		},
+		{
+			name:    "standby",
+			version: "16.0.0",
+			query:   autovacuumQuery,
+			row:     []driver.Value{true, 60.0, false, true},
+			expected: []MetricResult{
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1},
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 60},
+			},
+		},
+		{
+			name:    "pre 10",
+			version: "9.6.0",
+			query:   autovacuumQueryPre10,
+			row:     []driver.Value{false, 60.0, nil, false},
+			expected: []MetricResult{
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 0},
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 60},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error opening a stub db connection: %s", err)
+			}
+			defer db.Close()
+
+			inst := &instance{db: db, version: semver.MustParse(tt.version)}
+
+			rows := sqlmock.NewRows([]string{"enabled", "naptime_seconds", "launcher_up", "in_recovery"}).
+				AddRow(tt.row...)
+			mock.ExpectQuery(sanitizeQuery(tt.query)).WillReturnRows(rows)
+
+			ch := make(chan prometheus.Metric)
+			go func() {
+				defer close(ch)
+				c := PGAutovacuumCollector{}
+
+				if err := c.Update(context.Background(), inst, ch); err != nil {
+					t.Errorf("Error calling PGAutovacuumCollector.Update: %s", err)
+				}
+			}()
+
+			convey.Convey("Metrics comparison", t, func() {
+				for _, expect := range tt.expected {
+					m := readMetric(<-ch)
+					convey.So(expect, convey.ShouldResemble, m)
+				}
+				_, ok := <-ch
+				convey.So(ok, convey.ShouldBeFalse)
+			})
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const backendXminSubsystem = "backend_xmin"
+
+func init() {
+	// Creates one series per backend, so it is disabled by default and
+	// capped by --collector.backend_xmin.limit.
+	registerCollector(backendXminSubsystem, defaultDisabled, NewPGBackendXminCollector)
+}
+
+var backendXminLimit = kingpin.Flag("collector.backend_xmin.limit", "Maximum number of backends to report, oldest xmin or xid first.").Default("10").Int()
+
+type PGBackendXminCollector struct {
+	log       log.Logger
+	databases databaseFilter
+	limit     int
+}
+
+func NewPGBackendXminCollector(config collectorConfig) (Collector, error) {
+	return &PGBackendXminCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		limit:     *backendXminLimit,
+	}, nil
+}
+
+var (
+	backendXminAge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "backend_xmin_age"),
+		"Age in transactions of the backend's xmin horizon. The backend with the oldest xmin holds back the removal of dead tuples by vacuum",
+		[]string{"pid", "datname", "usename", "state"},
+		prometheus.Labels{},
+	)
+	backendXidAge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "backend_xid_age"),
+		"Age in transactions of the backend's top-level transaction ID, if it has been assigned one",
+		[]string{"pid", "datname", "usename", "state"},
+		prometheus.Labels{},
+	)
+
+	backendXminQuery = `
+	SELECT
+		pid::text AS pid,
+		datname,
+		usename,
+		state,
+		age(backend_xmin) AS xmin_age,
+		age(backend_xid) AS xid_age
+	FROM pg_catalog.pg_stat_activity
+	WHERE (backend_xmin IS NOT NULL OR backend_xid IS NOT NULL)
+		AND pid <> pg_backend_pid()%s
+	ORDER BY GREATEST(age(backend_xmin), age(backend_xid)) DESC
+	LIMIT $1
+	`
+)
+
+func (c *PGBackendXminCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// backend_xmin and backend_xid were added in PostgreSQL 9.4
+	if instance.version.LT(semver.MustParse("9.4.0")) {
+		level.Debug(c.log).Log("msg", "backend_xmin is not available before PostgreSQL 9.4")
+		return nil
+	}
+
+	db := instance.getDB()
+	databases, args := c.databases.and("datname", []any{c.limit})
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(backendXminQuery, databases), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pid, datname, usename, state sql.NullString
+		var xminAge, xidAge sql.NullInt64
+
+		if err := rows.Scan(&pid, &datname, &usename, &state, &xminAge, &xidAge); err != nil {
+			return err
+		}
+
+		if !pid.Valid {
+			level.Debug(c.log).Log("msg", "Skipping backend without pid")
+			continue
+		}
+
+		// Background workers have no database or user.
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		usenameLabel := "unknown"
+		if usename.Valid {
+			usenameLabel = usename.String
+		}
+		stateLabel := "unknown"
+		if state.Valid {
+			stateLabel = state.String
+		}
+		labels := []string{pid.String, datnameLabel, usenameLabel, stateLabel}
+
+		if xminAge.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				backendXminAge,
+				prometheus.GaugeValue,
+				float64(xminAge.Int64),
+				labels...,
+			)
+		}
+		if xidAge.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				backendXidAge,
+				prometheus.GaugeValue,
+				float64(xidAge.Int64),
+				labels...,
+			)
+		}
+	}
+	return rows.Err()
+}
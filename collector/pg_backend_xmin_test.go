@@ -0,0 +1,152 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGBackendXminCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	columns := []string{"pid", "datname", "usename", "state", "xmin_age", "xid_age"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("4242", "postgres", "app", "idle in transaction", 150000, 150002).
+		AddRow(nil, "postgres", "app", "active", 100, nil).
+		AddRow("4100", nil, nil, nil, 3000, nil)
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(backendXminQuery, ""))).WithArgs(10).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGBackendXminCollector{
+			log:   log.With(log.NewNopLogger(), "collector", "backend_xmin"),
+			limit: 10,
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGBackendXminCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"pid": "4242", "datname": "postgres", "usename": "app", "state": "idle in transaction"}, metricType: dto.MetricType_GAUGE, value: 150000},
+		{labels: labelMap{"pid": "4242", "datname": "postgres", "usename": "app", "state": "idle in transaction"}, metricType: dto.MetricType_GAUGE, value: 150002},
+		{labels: labelMap{"pid": "4100", "datname": "unknown", "usename": "unknown", "state": "unknown"}, metricType: dto.MetricType_GAUGE, value: 3000},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGBackendXminCollectorPre94(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("9.3.0")}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGBackendXminCollector{
+			log:   log.With(log.NewNopLogger(), "collector", "backend_xmin"),
+			limit: 10,
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGBackendXminCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No metrics before PostgreSQL 9.4", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGBackendXminCollectorDatabaseFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	c := PGBackendXminCollector{
+		log:       log.With(log.NewNopLogger(), "collector", "backend_xmin"),
+		limit:     10,
+		databases: newDatabaseFilter([]string{"app"}, nil),
+	}
+	databases, _ := c.databases.and("datname", []any{c.limit})
+
+	columns := []string{"pid", "datname", "usename", "state", "xmin_age", "xid_age"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("4242", "app", "app", "idle in transaction", 150000, nil)
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(backendXminQuery, databases))).
+		WithArgs(10, `{"app"}`).
+		WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGBackendXminCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"pid": "4242", "datname": "app", "usename": "app", "state": "idle in transaction"}, metricType: dto.MetricType_GAUGE, value: 150000},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
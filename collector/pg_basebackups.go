@@ -0,0 +1,74 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const basebackupsSubsystem = "basebackups"
+
+func init() {
+	registerCollector(basebackupsSubsystem, defaultEnabled, NewPGBasebackupsCollector)
+}
+
+type PGBasebackupsCollector struct {
+	log log.Logger
+}
+
+func NewPGBasebackupsCollector(config collectorConfig) (Collector, error) {
+	return &PGBasebackupsCollector{log: config.logger}, nil
+}
+
+var (
+	basebackupsInProgress = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, basebackupsSubsystem, "in_progress"),
+		"Number of base backups currently being streamed by WAL senders",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	basebackupsInProgressQuery = `SELECT count(*) AS in_progress FROM pg_catalog.pg_stat_progress_basebackup`
+)
+
+func (c *PGBasebackupsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_stat_progress_basebackup was added in PostgreSQL 13
+	if instance.version.LT(semver.MustParse("13.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_stat_progress_basebackup is not available before PostgreSQL 13")
+		return nil
+	}
+
+	db := instance.getDB()
+	var inProgress sql.NullInt64
+	if err := db.QueryRowContext(ctx, basebackupsInProgressQuery).Scan(&inProgress); err != nil {
+		return err
+	}
+
+	inProgressMetric := 0.0
+	if inProgress.Valid {
+		inProgressMetric = float64(inProgress.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		basebackupsInProgress,
+		prometheus.GaugeValue,
+		inProgressMetric,
+	)
+	return nil
+}
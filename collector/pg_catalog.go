@@ -0,0 +1,107 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const catalogSubsystem = "catalog"
+
+func init() {
+	registerCollector(catalogSubsystem, defaultDisabled, NewPGCatalogCollector)
+}
+
+type PGCatalogCollector struct {
+	log log.Logger
+}
+
+func NewPGCatalogCollector(config collectorConfig) (Collector, error) {
+	return &PGCatalogCollector{log: config.logger}, nil
+}
+
+var (
+	catalogRelationSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, catalogSubsystem, "relation_size_bytes"),
+		"Total disk space used by the system catalog table, including indexes and TOAST data",
+		[]string{"relname"},
+		prometheus.Labels{},
+	)
+
+	// The catalogs which grow with DDL and temporary table churn.
+	catalogRelationSizeQuery = `
+	SELECT
+		c.relname,
+		pg_total_relation_size(c.oid) AS size_bytes
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'pg_catalog'
+	AND c.relname IN (
+		'pg_attribute',
+		'pg_attrdef',
+		'pg_class',
+		'pg_constraint',
+		'pg_depend',
+		'pg_description',
+		'pg_index',
+		'pg_inherits',
+		'pg_proc',
+		'pg_statistic',
+		'pg_type'
+	)
+	`
+)
+
+func (c *PGCatalogCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		catalogRelationSizeQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var relname sql.NullString
+		var sizeBytes sql.NullFloat64
+
+		if err := rows.Scan(&relname, &sizeBytes); err != nil {
+			return err
+		}
+
+		if !relname.Valid {
+			continue
+		}
+
+		sizeBytesMetric := 0.0
+		if sizeBytes.Valid {
+			sizeBytesMetric = sizeBytes.Float64
+		}
+		ch <- prometheus.MustNewConstMetric(
+			catalogRelationSizeBytes,
+			prometheus.GaugeValue,
+			sizeBytesMetric,
+			relname.String,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const checkpointSubsystem = "checkpoint"
+
+func init() {
+	registerCollector(checkpointSubsystem, defaultDisabled, NewPGCheckpointCollector)
+}
+
+type PGCheckpointCollector struct {
+	log log.Logger
+}
+
+func NewPGCheckpointCollector(config collectorConfig) (Collector, error) {
+	return &PGCheckpointCollector{log: config.logger}, nil
+}
+
+var (
+	checkpointDistanceBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, checkpointSubsystem, "distance_bytes"),
+		"WAL written since the redo location of the last checkpoint, in bytes. This is the amount of WAL to replay after a crash",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	checkpointDistanceQuery = `
+	SELECT
+		pg_wal_lsn_diff(
+			CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn()
+			ELSE pg_current_wal_lsn()
+			END,
+			redo_lsn
+		) AS distance_bytes
+	FROM pg_control_checkpoint()
+	`
+
+	checkpointDistanceQueryPre10 = `
+	SELECT
+		pg_xlog_location_diff(
+			CASE WHEN pg_is_in_recovery() THEN pg_last_xlog_replay_location()
+			ELSE pg_current_xlog_location()
+			END,
+			redo_location
+		) AS distance_bytes
+	FROM pg_control_checkpoint()
+	`
+)
+
+func (c *PGCheckpointCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_control_checkpoint was added in PostgreSQL 9.6
+	if instance.version.LT(semver.MustParse("9.6.0")) {
+		level.Debug(c.log).Log("msg", "pg_control_checkpoint is not available before PostgreSQL 9.6")
+		return nil
+	}
+
+	query := checkpointDistanceQueryPre10
+	if instance.version.GE(semver.MustParse("10.0.0")) {
+		query = checkpointDistanceQuery
+	}
+
+	db := instance.getDB()
+	row := db.QueryRowContext(ctx, query)
+
+	var distanceBytes sql.NullFloat64
+	if err := row.Scan(&distanceBytes); err != nil {
+		return err
+	}
+	if !distanceBytes.Valid {
+		return ErrNoData
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		checkpointDistanceBytes,
+		prometheus.GaugeValue,
+		distanceBytes.Float64,
+	)
+	return nil
+}
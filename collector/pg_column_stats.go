@@ -0,0 +1,129 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const columnStatsSubsystem = "column_stats"
+
+func init() {
+	// Scans pg_attribute, which can be large on schemas with many tables.
+	registerCollector(columnStatsSubsystem, defaultDisabled, NewPGColumnStatsCollector)
+}
+
+var columnStatsMinRows = kingpin.Flag("collector.column_stats.min-rows", "Only report tables with at least this many estimated rows.").Default("10000").Int64()
+
+type PGColumnStatsCollector struct {
+	log       log.Logger
+	databases databaseFilter
+	minRows   int64
+}
+
+func NewPGColumnStatsCollector(config collectorConfig) (Collector, error) {
+	return &PGColumnStatsCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		minRows:   *columnStatsMinRows,
+	}, nil
+}
+
+var (
+	columnsStatsDisabled = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "columns", "stats_disabled"),
+		"Number of columns of the table with statistics collection disabled (attstattarget = 0)",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
+
+	columnStatsDisabledQuery = `
+	SELECT
+		current_database() AS datname,
+		n.nspname AS schemaname,
+		c.relname,
+		count(*) AS columns
+	FROM pg_catalog.pg_attribute a
+	JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE a.attnum > 0
+	AND NOT a.attisdropped
+	AND a.attstattarget = 0
+	AND c.relkind IN ('r', 'm', 'p')
+	AND c.reltuples >= $1
+	AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+	GROUP BY n.nspname, c.relname
+	`
+)
+
+func (c *PGColumnStatsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx,
+		columnStatsDisabledQuery, c.minRows)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, schemaname, relname sql.NullString
+		var columns sql.NullInt64
+
+		if err := rows.Scan(&datname, &schemaname, &relname, &columns); err != nil {
+			return err
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		schemanameLabel := "unknown"
+		if schemaname.Valid {
+			schemanameLabel = schemaname.String
+		}
+		relnameLabel := "unknown"
+		if relname.Valid {
+			relnameLabel = relname.String
+		}
+
+		columnsMetric := 0.0
+		if columns.Valid {
+			columnsMetric = float64(columns.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			columnsStatsDisabled,
+			prometheus.GaugeValue,
+			columnsMetric,
+			datnameLabel, schemanameLabel, relnameLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
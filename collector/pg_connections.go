@@ -0,0 +1,111 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const connectionsSubsystem = "connections"
+
+func init() {
+	registerCollector(connectionsSubsystem, defaultEnabled, NewPGConnectionsCollector)
+}
+
+// PGConnectionsCollector compares the number of client connections with the
+// number of connection slots available to non-superusers, so that running
+// out of connections can be alerted on before it happens.
+type PGConnectionsCollector struct {
+	log log.Logger
+}
+
+func NewPGConnectionsCollector(config collectorConfig) (Collector, error) {
+	return &PGConnectionsCollector{log: config.logger}, nil
+}
+
+var (
+	connectionsUsed = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connectionsSubsystem, "used"),
+		"Number of client backends currently connected",
+		[]string{},
+		prometheus.Labels{},
+	)
+	connectionsMax = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connectionsSubsystem, "max"),
+		"Connection slots available to non-superusers: max_connections minus superuser_reserved_connections and reserved_connections",
+		[]string{},
+		prometheus.Labels{},
+	)
+	connectionsUtilization = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connectionsSubsystem, "utilization_ratio"),
+		"Client backends divided by the connection slots available to non-superusers",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	// reserved_connections was added in PostgreSQL 16; current_setting
+	// returns NULL for it on older versions.
+	connectionsQuery = `
+	SELECT
+		(SELECT count(*) FROM pg_catalog.pg_stat_activity WHERE backend_type = 'client backend') AS used,
+		current_setting('max_connections')::int
+			- current_setting('superuser_reserved_connections')::int
+			- COALESCE(current_setting('reserved_connections', true)::int, 0) AS max_connections
+	`
+
+	// Before PostgreSQL 10 pg_stat_activity only lists client backends.
+	connectionsQueryPre10 = `
+	SELECT
+		(SELECT count(*) FROM pg_catalog.pg_stat_activity) AS used,
+		current_setting('max_connections')::int
+			- current_setting('superuser_reserved_connections')::int AS max_connections
+	`
+)
+
+func (c *PGConnectionsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	query := connectionsQuery
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		query = connectionsQueryPre10
+	}
+
+	db := instance.getDB()
+	var used, maxConnections sql.NullInt64
+	if err := db.QueryRowContext(ctx, query).Scan(&used, &maxConnections); err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		connectionsUsed,
+		prometheus.GaugeValue,
+		float64(used.Int64),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		connectionsMax,
+		prometheus.GaugeValue,
+		float64(maxConnections.Int64),
+	)
+	if maxConnections.Int64 > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			connectionsUtilization,
+			prometheus.GaugeValue,
+			float64(used.Int64)/float64(maxConnections.Int64),
+		)
+	}
+	return nil
+}
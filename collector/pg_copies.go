@@ -0,0 +1,100 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const copiesSubsystem = "copies"
+
+func init() {
+	registerCollector(copiesSubsystem, defaultEnabled, NewPGCopiesCollector)
+}
+
+type PGCopiesCollector struct {
+	log log.Logger
+}
+
+func NewPGCopiesCollector(config collectorConfig) (Collector, error) {
+	return &PGCopiesCollector{log: config.logger}, nil
+}
+
+var (
+	copiesInProgress = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, copiesSubsystem, "in_progress"),
+		"Number of COPY commands currently running",
+		[]string{"command"},
+		prometheus.Labels{},
+	)
+
+	// Both commands are always reported so that an idle server exposes
+	// zeros rather than no series at all.
+	copiesInProgressQuery = `
+	SELECT
+		c.command,
+		count(p.pid) AS in_progress
+	FROM (VALUES ('COPY FROM'), ('COPY TO')) AS c(command)
+	LEFT JOIN pg_catalog.pg_stat_progress_copy p ON p.command = c.command
+	GROUP BY c.command
+	ORDER BY c.command
+	`
+)
+
+func (c *PGCopiesCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_stat_progress_copy was added in PostgreSQL 14
+	if instance.version.LT(semver.MustParse("14.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_stat_progress_copy is not available before PostgreSQL 14")
+		return nil
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		copiesInProgressQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var command sql.NullString
+		var inProgress sql.NullInt64
+		if err := rows.Scan(&command, &inProgress); err != nil {
+			return err
+		}
+
+		commandLabel := "unknown"
+		if command.Valid {
+			commandLabel = command.String
+		}
+		inProgressMetric := 0.0
+		if inProgress.Valid {
+			inProgressMetric = float64(inProgress.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			copiesInProgress,
+			prometheus.GaugeValue,
+			inProgressMetric,
+			commandLabel,
+		)
+	}
+	return rows.Err()
+}
@@ -16,30 +16,34 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const databaseSubsystem = "database"
 
+var (
+	databaseSizeConnectableOnly = kingpin.Flag("collector.database.size-connectable-only", "Only query the size of non-template databases that accept connections.").Default("false").Bool()
+)
+
 func init() {
 	registerCollector(databaseSubsystem, defaultEnabled, NewPGDatabaseCollector)
 }
 
 type PGDatabaseCollector struct {
-	log               log.Logger
-	excludedDatabases []string
+	log                 log.Logger
+	databases           databaseFilter
+	sizeConnectableOnly bool
 }
 
 func NewPGDatabaseCollector(config collectorConfig) (Collector, error) {
-	exclude := config.excludeDatabases
-	if exclude == nil {
-		exclude = []string{}
-	}
 	return &PGDatabaseCollector{
-		log:               config.logger,
-		excludedDatabases: exclude,
+		log:                 config.logger,
+		databases:           newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		sizeConnectableOnly: *databaseSizeConnectableOnly,
 	}, nil
 }
 
@@ -62,24 +66,43 @@ var (
 		"Connection limit set for the database",
 		[]string{"datname"}, nil,
 	)
+	pgDatabaseAllowConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(
+			namespace,
+			databaseSubsystem,
+			"allow_connections",
+		),
+		"Whether the database accepts connections: 0 if datallowconn is false or the database is marked invalid",
+		[]string{"datname"}, nil,
+	)
 
-	pgDatabaseQuery     = "SELECT pg_database.datname, pg_database.datconnlimit FROM pg_database;"
 	pgDatabaseSizeQuery = "SELECT pg_database_size($1)"
 )
 
-// Update implements Collector and exposes database size and connection limits.
+func pgDatabaseQuery(where string) string {
+	return fmt.Sprintf("SELECT pg_database.datname, pg_database.datconnlimit, pg_database.datallowconn, pg_database.datistemplate FROM pg_database%s;", where)
+}
+
+// datconnlimit is set to this value for databases left invalid by an
+// interrupted DROP DATABASE.
+const pgDatabaseInvalidConnLimit = -2
+
+// Update implements Collector and exposes database size, connection limits
+// and whether the database accepts connections.
 // It is called by the Prometheus registry when collecting metrics.
-// The list of databases is retrieved from pg_database and filtered
-// by the excludeDatabase config parameter. The tradeoff here is that
+// The list of databases is retrieved from pg_database, filtered
+// by the databases filter in the query. The tradeoff here is that
 // we have to query the list of databases and then query the size of
-// each database individually. This is because we can't filter the
-// list of databases in the query because the list of excluded
-// databases is dynamic.
+// each database individually. With sizeConnectableOnly set, templates and
+// databases that do not accept connections are left out of the size
+// queries, which can be slow on servers with many databases.
 func (c PGDatabaseCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
 	db := instance.getDB()
 	// Query the list of databases
+	where, args := c.databases.where()
 	rows, err := db.QueryContext(ctx,
-		pgDatabaseQuery,
+		pgDatabaseQuery(where),
+		args...,
 	)
 	if err != nil {
 		return err
@@ -91,7 +114,9 @@ func (c PGDatabaseCollector) Update(ctx context.Context, instance *instance, ch
 	for rows.Next() {
 		var datname sql.NullString
 		var connLimit sql.NullInt64
-		if err := rows.Scan(&datname, &connLimit); err != nil {
+		var allowConn sql.NullBool
+		var isTemplate sql.NullBool
+		if err := rows.Scan(&datname, &connLimit, &allowConn, &isTemplate); err != nil {
 			return err
 		}
 
@@ -99,14 +124,6 @@ func (c PGDatabaseCollector) Update(ctx context.Context, instance *instance, ch
 			continue
 		}
 		database := datname.String
-		// Ignore excluded databases
-		// Filtering is done here instead of in the query to avoid
-		// a complicated NOT IN query with a variable number of parameters
-		if sliceContains(c.excludedDatabases, database) {
-			continue
-		}
-
-		databases = append(databases, database)
 
 		connLimitMetric := 0.0
 		if connLimit.Valid {
@@ -116,6 +133,23 @@ func (c PGDatabaseCollector) Update(ctx context.Context, instance *instance, ch
 			pgDatabaseConnectionLimitsDesc,
 			prometheus.GaugeValue, connLimitMetric, database,
 		)
+
+		allowConnMetric := 0.0
+		if allowConn.Bool && connLimit.Int64 != pgDatabaseInvalidConnLimit {
+			allowConnMetric = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(
+			pgDatabaseAllowConnectionsDesc,
+			prometheus.GaugeValue, allowConnMetric, database,
+		)
+
+		if c.sizeConnectableOnly && (allowConnMetric == 0 || isTemplate.Bool) {
+			continue
+		}
+		databases = append(databases, database)
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
 	// Query the size of the databases
@@ -136,7 +170,7 @@ func (c PGDatabaseCollector) Update(ctx context.Context, instance *instance, ch
 		)
 
 	}
-	return rows.Err()
+	return nil
 }
 
 func sliceContains(slice []string, s string) bool {
@@ -31,8 +31,8 @@ func TestPGDatabaseCollector(t *testing.T) {
 
 	inst := &instance{db: db}
 
-	mock.ExpectQuery(sanitizeQuery(pgDatabaseQuery)).WillReturnRows(sqlmock.NewRows([]string{"datname", "datconnlimit"}).
-		AddRow("postgres", 15))
+	mock.ExpectQuery(sanitizeQuery(pgDatabaseQuery(""))).WillReturnRows(sqlmock.NewRows([]string{"datname", "datconnlimit", "datallowconn", "datistemplate"}).
+		AddRow("postgres", 15, true, false))
 
 	mock.ExpectQuery(sanitizeQuery(pgDatabaseSizeQuery)).WithArgs("postgres").WillReturnRows(sqlmock.NewRows([]string{"pg_database_size"}).
 		AddRow(1024))
@@ -48,6 +48,7 @@ func TestPGDatabaseCollector(t *testing.T) {
 
 	expected := []MetricResult{
 		{labels: labelMap{"datname": "postgres"}, value: 15, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "postgres"}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"datname": "postgres"}, value: 1024, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
@@ -72,8 +73,8 @@ func TestPGDatabaseCollectorNullMetric(t *testing.T) {
 
 	inst := &instance{db: db}
 
-	mock.ExpectQuery(sanitizeQuery(pgDatabaseQuery)).WillReturnRows(sqlmock.NewRows([]string{"datname", "datconnlimit"}).
-		AddRow("postgres", nil))
+	mock.ExpectQuery(sanitizeQuery(pgDatabaseQuery(""))).WillReturnRows(sqlmock.NewRows([]string{"datname", "datconnlimit", "datallowconn", "datistemplate"}).
+		AddRow("postgres", nil, nil, nil))
 
 	mock.ExpectQuery(sanitizeQuery(pgDatabaseSizeQuery)).WithArgs("postgres").WillReturnRows(sqlmock.NewRows([]string{"pg_database_size"}).
 		AddRow(nil))
@@ -90,6 +91,106 @@ func TestPGDatabaseCollectorNullMetric(t *testing.T) {
 	expected := []MetricResult{
 		{labels: labelMap{"datname": "postgres"}, value: 0, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"datname": "postgres"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "postgres"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGDatabaseCollectorAllowConnections(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	mock.ExpectQuery(sanitizeQuery(pgDatabaseQuery(""))).WillReturnRows(sqlmock.NewRows([]string{"datname", "datconnlimit", "datallowconn", "datistemplate"}).
+		AddRow("template0", -1, false, true).
+		AddRow("dropped", -2, true, false))
+
+	mock.ExpectQuery(sanitizeQuery(pgDatabaseSizeQuery)).WithArgs("template0").WillReturnRows(sqlmock.NewRows([]string{"pg_database_size"}).
+		AddRow(2048))
+	mock.ExpectQuery(sanitizeQuery(pgDatabaseSizeQuery)).WithArgs("dropped").WillReturnRows(sqlmock.NewRows([]string{"pg_database_size"}).
+		AddRow(4096))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGDatabaseCollector{}
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGDatabaseCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "template0"}, value: -1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "template0"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "dropped"}, value: -2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "dropped"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "template0"}, value: 2048, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "dropped"}, value: 4096, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGDatabaseCollectorSizeConnectableOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	c := PGDatabaseCollector{
+		databases:           newDatabaseFilter(nil, []string{"tenant_1"}),
+		sizeConnectableOnly: true,
+	}
+	where, _ := c.databases.where()
+
+	mock.ExpectQuery(sanitizeQuery(pgDatabaseQuery(where))).
+		WithArgs(`{"tenant_1"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"datname", "datconnlimit", "datallowconn", "datistemplate"}).
+			AddRow("postgres", -1, true, false).
+			AddRow("template1", -1, true, true).
+			AddRow("template0", -1, false, true))
+
+	mock.ExpectQuery(sanitizeQuery(pgDatabaseSizeQuery)).WithArgs("postgres").WillReturnRows(sqlmock.NewRows([]string{"pg_database_size"}).
+		AddRow(1024))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGDatabaseCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "postgres"}, value: -1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "postgres"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "template1"}, value: -1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "template1"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "template0"}, value: -1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "template0"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "postgres"}, value: 1024, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range expected {
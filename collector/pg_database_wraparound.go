@@ -16,7 +16,9 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
@@ -28,12 +30,27 @@ func init() {
 	registerCollector(databaseWraparoundSubsystem, defaultDisabled, NewPGDatabaseWraparoundCollector)
 }
 
+// xidWraparoundLimit is the number of transaction IDs a database can consume
+// past its datfrozenxid before it wraps around.
+const xidWraparoundLimit = 1 << 31
+
+var databaseWraparoundEmergencyFraction = kingpin.Flag("collector.database_wraparound.emergency-fraction", "Fraction of the transaction ID wraparound limit at which a database is reported as in wraparound emergency.").Default("0.9").Float64()
+
 type PGDatabaseWraparoundCollector struct {
-	log log.Logger
+	log               log.Logger
+	databases         databaseFilter
+	emergencyFraction float64
 }
 
 func NewPGDatabaseWraparoundCollector(config collectorConfig) (Collector, error) {
-	return &PGDatabaseWraparoundCollector{log: config.logger}, nil
+	if *databaseWraparoundEmergencyFraction <= 0 || *databaseWraparoundEmergencyFraction > 1 {
+		return nil, fmt.Errorf("--collector.database_wraparound.emergency-fraction must be in (0, 1], got %v", *databaseWraparoundEmergencyFraction)
+	}
+	return &PGDatabaseWraparoundCollector{
+		log:               config.logger,
+		databases:         newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		emergencyFraction: *databaseWraparoundEmergencyFraction,
+	}, nil
 }
 
 var (
@@ -49,6 +66,18 @@ var (
 		[]string{"datname"},
 		prometheus.Labels{},
 	)
+	wraparoundEmergency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "wraparound", "emergency"),
+		"Whether the age of the oldest unfrozen transaction ID exceeds the configured fraction of the wraparound limit (1 = emergency, 0 = ok).",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
+	databaseXidRemaining = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "database", "xid_remaining"),
+		"Number of transaction IDs that can be consumed before the database reaches the wraparound limit.",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
 
 	databaseWraparoundQuery = `
 	SELECT
@@ -79,6 +108,9 @@ func (c *PGDatabaseWraparoundCollector) Update(ctx context.Context, instance *in
 		if err := rows.Scan(&datname, &ageDatfrozenxid, &ageDatminmxid); err != nil {
 			return err
 		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
 
 		if !datname.Valid {
 			level.Debug(c.log).Log("msg", "Skipping database with NULL name")
@@ -107,6 +139,22 @@ func (c *PGDatabaseWraparoundCollector) Update(ctx context.Context, instance *in
 			prometheus.GaugeValue,
 			ageDatminmxidMetric, datname.String,
 		)
+
+		emergencyMetric := 0.0
+		if ageDatfrozenxidMetric > c.emergencyFraction*xidWraparoundLimit {
+			emergencyMetric = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(
+			wraparoundEmergency,
+			prometheus.GaugeValue,
+			emergencyMetric, datname.String,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			databaseXidRemaining,
+			prometheus.GaugeValue,
+			xidWraparoundLimit-ageDatfrozenxidMetric, datname.String,
+		)
 	}
 	if err := rows.Err(); err != nil {
 		return err
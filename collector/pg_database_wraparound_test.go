@@ -35,14 +35,15 @@ func TestPGDatabaseWraparoundCollector(t *testing.T) {
 		"age_datminmxid",
 	}
 	rows := sqlmock.NewRows(columns).
-		AddRow("newreddit", 87126426, 0)
+		AddRow("newreddit", 87126426, 0).
+		AddRow("oldreddit", 1990000000, 12)
 
 	mock.ExpectQuery(sanitizeQuery(databaseWraparoundQuery)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
 	go func() {
 		defer close(ch)
-		c := PGDatabaseWraparoundCollector{}
+		c := PGDatabaseWraparoundCollector{emergencyFraction: 0.9}
 
 		if err := c.Update(context.Background(), inst, ch); err != nil {
 			t.Errorf("Error calling PGDatabaseWraparoundCollector.Update: %s", err)
@@ -51,6 +52,12 @@ func TestPGDatabaseWraparoundCollector(t *testing.T) {
 	expected := []MetricResult{
 		{labels: labelMap{"datname": "newreddit"}, value: 87126426, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"datname": "newreddit"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "newreddit"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "newreddit"}, value: 2060357222, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "oldreddit"}, value: 1990000000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "oldreddit"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "oldreddit"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "oldreddit"}, value: 157483648, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range expected {
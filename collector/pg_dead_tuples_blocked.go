@@ -0,0 +1,93 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const deadTuplesBlockedSubsystem = "dead_tuples_blocked"
+
+func init() {
+	registerCollector(deadTuplesBlockedSubsystem, defaultDisabled, NewPGDeadTuplesBlockedCollector)
+}
+
+var (
+	deadTuplesBlockedDeadTupleThreshold = kingpin.Flag("collector.dead_tuples_blocked.dead-tuple-threshold", "Number of dead tuples in a single table above which dead tuples are considered high.").Default("100000").Int64()
+	deadTuplesBlockedXminAgeThreshold   = kingpin.Flag("collector.dead_tuples_blocked.xmin-age-threshold", "Age in transactions of the oldest backend xmin above which a snapshot is considered old.").Default("1000000").Int64()
+)
+
+// PGDeadTuplesBlockedCollector flags the case where vacuum cannot reclaim
+// dead tuples because a long-running transaction holds an old snapshot: a
+// table has many dead tuples while some backend's xmin is old.
+type PGDeadTuplesBlockedCollector struct {
+	log                log.Logger
+	deadTupleThreshold int64
+	xminAgeThreshold   int64
+}
+
+func NewPGDeadTuplesBlockedCollector(config collectorConfig) (Collector, error) {
+	return &PGDeadTuplesBlockedCollector{
+		log:                config.logger,
+		deadTupleThreshold: *deadTuplesBlockedDeadTupleThreshold,
+		xminAgeThreshold:   *deadTuplesBlockedXminAgeThreshold,
+	}, nil
+}
+
+var (
+	deadTuplesBlocked = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "dead_tuples", "blocked"),
+		"Whether a table in the current database has more dead tuples than the threshold while a backend holds a snapshot older than the xmin age threshold (1 = blocked, 0 = ok).",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	deadTuplesBlockedQuery = `
+	SELECT
+		(SELECT max(n_dead_tup) FROM pg_catalog.pg_stat_user_tables) AS max_dead_tuples,
+		(SELECT max(age(backend_xmin)) FROM pg_catalog.pg_stat_activity WHERE pid <> pg_backend_pid()) AS oldest_xmin_age
+	`
+)
+
+func (c *PGDeadTuplesBlockedCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// backend_xmin was added in PostgreSQL 9.4
+	if instance.version.LT(semver.MustParse("9.4.0")) {
+		level.Debug(c.log).Log("msg", "dead_tuples_blocked collector is not available on PostgreSQL < 9.4, skipping")
+		return nil
+	}
+
+	db := instance.getDB()
+	var maxDeadTuples, oldestXminAge sql.NullInt64
+	if err := db.QueryRowContext(ctx, deadTuplesBlockedQuery).Scan(&maxDeadTuples, &oldestXminAge); err != nil {
+		return err
+	}
+
+	blocked := 0.0
+	if maxDeadTuples.Int64 > c.deadTupleThreshold && oldestXminAge.Int64 > c.xminAgeThreshold {
+		blocked = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(
+		deadTuplesBlocked,
+		prometheus.GaugeValue,
+		blocked,
+	)
+	return nil
+}
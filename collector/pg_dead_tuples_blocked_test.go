@@ -0,0 +1,78 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGDeadTuplesBlockedCollector(t *testing.T) {
+	cases := []struct {
+		name          string
+		maxDeadTuples any
+		oldestXminAge any
+		want          float64
+	}{
+		{"blocked", 250000, 5000000, 1},
+		{"no old snapshot", 250000, 1200, 0},
+		{"few dead tuples", 500, 5000000, 0},
+		{"no backends", 250000, nil, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error opening a stub db connection: %s", err)
+			}
+			defer db.Close()
+
+			inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+			rows := sqlmock.NewRows([]string{"max_dead_tuples", "oldest_xmin_age"}).
+				AddRow(tc.maxDeadTuples, tc.oldestXminAge)
+			mock.ExpectQuery(sanitizeQuery(deadTuplesBlockedQuery)).WillReturnRows(rows)
+
+			ch := make(chan prometheus.Metric)
+			go func() {
+				defer close(ch)
+				c := PGDeadTuplesBlockedCollector{deadTupleThreshold: 100000, xminAgeThreshold: 1000000}
+
+				if err := c.Update(context.Background(), inst, ch); err != nil {
+					t.Errorf("Error calling PGDeadTuplesBlockedCollector.Update: %s", err)
+				}
+			}()
+
+			expected := []MetricResult{
+				{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: tc.want},
+			}
+
+			convey.Convey("Metrics comparison", t, func() {
+				for _, expect := range expected {
+					m := readMetric(<-ch)
+					convey.So(expect, convey.ShouldResemble, m)
+				}
+			})
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
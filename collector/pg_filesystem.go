@@ -0,0 +1,90 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const filesystemSubsystem = "filesystem"
+
+func init() {
+	// This collector reads the local filesystem, so it only makes sense when
+	// the exporter runs on the same host as the database.
+	registerCollector(filesystemSubsystem, defaultDisabled, NewPGFilesystemCollector)
+}
+
+var (
+	filesystemDataPath = kingpin.Flag("collector.filesystem.data-path", "Path of the PostgreSQL data directory. Only works when the exporter runs on the database host.").Default("").String()
+	filesystemWALPath  = kingpin.Flag("collector.filesystem.wal-path", "Path of the PostgreSQL WAL directory, if it is on a different filesystem than the data directory.").Default("").String()
+)
+
+type PGFilesystemCollector struct {
+	log      log.Logger
+	dataPath string
+	walPath  string
+}
+
+func NewPGFilesystemCollector(config collectorConfig) (Collector, error) {
+	if *filesystemDataPath == "" {
+		return nil, errors.New("filesystem collector requires --collector.filesystem.data-path")
+	}
+	return &PGFilesystemCollector{
+		log:      config.logger,
+		dataPath: *filesystemDataPath,
+		walPath:  *filesystemWALPath,
+	}, nil
+}
+
+var (
+	pgDataDirectoryFreeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "data_directory", "free_bytes"),
+		"Free space available to unprivileged users on the filesystem of the data directory",
+		[]string{"path"}, nil,
+	)
+	pgWALDirectoryFreeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "wal_directory", "free_bytes"),
+		"Free space available to unprivileged users on the filesystem of the WAL directory",
+		[]string{"path"}, nil,
+	)
+)
+
+func (c *PGFilesystemCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	free, err := filesystemFreeBytes(c.dataPath)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(
+		pgDataDirectoryFreeBytes,
+		prometheus.GaugeValue, float64(free), c.dataPath,
+	)
+
+	if c.walPath == "" {
+		return nil
+	}
+	free, err = filesystemFreeBytes(c.walPath)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(
+		pgWALDirectoryFreeBytes,
+		prometheus.GaugeValue, float64(free), c.walPath,
+	)
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPGFilesystemCollector(t *testing.T) {
+	dir := t.TempDir()
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGFilesystemCollector{dataPath: dir}
+
+		if err := c.Update(context.Background(), &instance{}, ch); err != nil {
+			t.Errorf("Error calling PGFilesystemCollector.Update: %s", err)
+		}
+	}()
+
+	var metrics []MetricResult
+	for m := range ch {
+		metrics = append(metrics, readMetric(m))
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(metrics))
+	}
+	if metrics[0].labels["path"] != dir || metrics[0].metricType != dto.MetricType_GAUGE {
+		t.Errorf("unexpected metric: %+v", metrics[0])
+	}
+	if metrics[0].value <= 0 {
+		t.Errorf("expected free space to be positive, got %f", metrics[0].value)
+	}
+}
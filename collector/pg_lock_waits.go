@@ -0,0 +1,137 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const lockWaitsSubsystem = "lock_waits"
+
+func init() {
+	// Creates one series per blocked/blocking backend pair, so it is
+	// disabled by default and capped by --collector.lock_waits.limit.
+	registerCollector(lockWaitsSubsystem, defaultDisabled, NewPGLockWaitsCollector)
+}
+
+var lockWaitsLimit = kingpin.Flag("collector.lock_waits.limit", "Maximum number of lock waits to report, longest waits first.").Default("20").Int()
+
+type PGLockWaitsCollector struct {
+	log   log.Logger
+	limit int
+}
+
+func NewPGLockWaitsCollector(config collectorConfig) (Collector, error) {
+	return &PGLockWaitsCollector{
+		log:   config.logger,
+		limit: *lockWaitsLimit,
+	}, nil
+}
+
+var (
+	lockWaitSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "lock", "wait_seconds"),
+		"Time the blocked backend has been waiting on a lock held by the blocking backend, in seconds",
+		[]string{"blocked_pid", "blocking_pid", "locktype"},
+		prometheus.Labels{},
+	)
+
+	// pg_locks.waitstart was added in PostgreSQL 14. Older versions fall
+	// back to the start of the blocked query.
+	lockWaitsQuery      = lockWaitsQueryWith("COALESCE(l.waitstart, a.query_start)")
+	lockWaitsQueryPre14 = lockWaitsQueryWith("a.query_start")
+)
+
+// lockWaitsQueryWith returns the lock waits query measuring the wait from
+// waitstart. pg_blocking_pids() can report the same blocking pid more than
+// once, for example for parallel workers, so rows are grouped to emit each
+// blocked/blocking/locktype combination once.
+func lockWaitsQueryWith(waitstart string) string {
+	return fmt.Sprintf(`
+	SELECT
+		l.pid::text AS blocked_pid,
+		blocking.pid::text AS blocking_pid,
+		l.locktype,
+		max(EXTRACT(EPOCH FROM (now() - %s))) AS wait_seconds
+	FROM pg_catalog.pg_locks l
+	JOIN pg_catalog.pg_stat_activity a ON a.pid = l.pid
+	CROSS JOIN LATERAL unnest(pg_blocking_pids(l.pid)) AS blocking(pid)
+	WHERE NOT l.granted
+	GROUP BY l.pid, blocking.pid, l.locktype
+	ORDER BY wait_seconds DESC
+	LIMIT $1
+	`, waitstart)
+}
+
+func (c *PGLockWaitsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_blocking_pids was added in PostgreSQL 9.6
+	if instance.version.LT(semver.MustParse("9.6.0")) {
+		level.Debug(c.log).Log("msg", "pg_blocking_pids is not available before PostgreSQL 9.6")
+		return nil
+	}
+
+	query := lockWaitsQueryPre14
+	if instance.version.GE(semver.MustParse("14.0.0")) {
+		query = lockWaitsQuery
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx, query, c.limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blockedPid, blockingPid, locktype sql.NullString
+		var waitSeconds sql.NullFloat64
+
+		if err := rows.Scan(&blockedPid, &blockingPid, &locktype, &waitSeconds); err != nil {
+			return err
+		}
+
+		if !blockedPid.Valid || !blockingPid.Valid {
+			level.Debug(c.log).Log("msg", "Skipping lock wait without pid")
+			continue
+		}
+
+		locktypeLabel := "unknown"
+		if locktype.Valid {
+			locktypeLabel = locktype.String
+		}
+		waitSecondsMetric := 0.0
+		if waitSeconds.Valid {
+			waitSecondsMetric = waitSeconds.Float64
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			lockWaitSeconds,
+			prometheus.GaugeValue,
+			waitSecondsMetric,
+			blockedPid.String, blockingPid.String, locktypeLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
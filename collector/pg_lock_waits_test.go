@@ -0,0 +1,111 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGLockWaitsCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	rows := sqlmock.NewRows([]string{"blocked_pid", "blocking_pid", "locktype", "wait_seconds"}).
+		AddRow("4242", "4100", "relation", 12.5).
+		AddRow(nil, "4100", "relation", 3.0).
+		AddRow("4243", "4242", nil, 1.5)
+	mock.ExpectQuery(sanitizeQuery(lockWaitsQuery)).WithArgs(5).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGLockWaitsCollector{
+			log:   log.With(log.NewNopLogger(), "collector", "lock_waits"),
+			limit: 5,
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGLockWaitsCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"blocked_pid": "4242", "blocking_pid": "4100", "locktype": "relation"}, metricType: dto.MetricType_GAUGE, value: 12.5},
+		{labels: labelMap{"blocked_pid": "4243", "blocking_pid": "4242", "locktype": "unknown"}, metricType: dto.MetricType_GAUGE, value: 1.5},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGLockWaitsCollectorPre14(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("13.3.0")}
+
+	rows := sqlmock.NewRows([]string{"blocked_pid", "blocking_pid", "locktype", "wait_seconds"}).
+		AddRow("4242", "4100", "transactionid", 7.0)
+	mock.ExpectQuery(sanitizeQuery(lockWaitsQueryPre14)).WithArgs(20).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGLockWaitsCollector{
+			log:   log.With(log.NewNopLogger(), "collector", "lock_waits"),
+			limit: 20,
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGLockWaitsCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"blocked_pid": "4242", "blocking_pid": "4100", "locktype": "transactionid"}, metricType: dto.MetricType_GAUGE, value: 7},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -28,12 +28,14 @@ func init() {
 }
 
 type PGLocksCollector struct {
-	log log.Logger
+	log       log.Logger
+	databases databaseFilter
 }
 
 func NewPGLocksCollector(config collectorConfig) (Collector, error) {
 	return &PGLocksCollector{
-		log: config.logger,
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
 	}, nil
 }
 
@@ -47,6 +49,15 @@ var (
 		"Number of locks",
 		[]string{"datname", "mode"}, nil,
 	)
+	pgLocksByLocktypeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(
+			namespace,
+			locksSubsystem,
+			"by_locktype",
+		),
+		"Number of locks by lock mode and type, split into granted and awaited locks",
+		[]string{"datname", "mode", "locktype", "granted"}, nil,
+	)
 
 	pgLocksQuery = `
 		SELECT 
@@ -84,9 +95,24 @@ var (
 		ORDER BY 
 		  1
 	`
+
+	// Locks on transaction IDs and other objects that are not scoped to a
+	// database have a NULL database.
+	pgLocksByLocktypeQuery = `
+	SELECT
+		pg_database.datname,
+		lower(pg_locks.mode) AS mode,
+		pg_locks.locktype,
+		pg_locks.granted,
+		count(*) AS count
+	FROM pg_locks
+	LEFT JOIN pg_database ON pg_database.oid = pg_locks.database
+	GROUP BY pg_database.datname, lower(pg_locks.mode), pg_locks.locktype, pg_locks.granted
+	`
 )
 
-// Update implements Collector and exposes database locks.
+// Update implements Collector and exposes database locks by mode, and all
+// locks by mode, type and whether they have been granted.
 // It is called by the Prometheus registry when collecting metrics.
 func (c PGLocksCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
 	db := instance.getDB()
@@ -106,6 +132,9 @@ func (c PGLocksCollector) Update(ctx context.Context, instance *instance, ch cha
 		if err := rows.Scan(&datname, &mode, &count); err != nil {
 			return err
 		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
 
 		if !datname.Valid || !mode.Valid {
 			continue
@@ -125,5 +154,46 @@ func (c PGLocksCollector) Update(ctx context.Context, instance *instance, ch cha
 	if err := rows.Err(); err != nil {
 		return err
 	}
-	return nil
+	return c.updateLocktypes(ctx, db, ch)
+}
+
+func (c PGLocksCollector) updateLocktypes(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		pgLocksByLocktypeQuery,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, mode, locktype sql.NullString
+		var granted sql.NullBool
+		var count sql.NullInt64
+		if err := rows.Scan(&datname, &mode, &locktype, &granted, &count); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		if !mode.Valid || !locktype.Valid {
+			continue
+		}
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		grantedLabel := "false"
+		if granted.Bool {
+			grantedLabel = "true"
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			pgLocksByLocktypeDesc,
+			prometheus.GaugeValue, float64(count.Int64),
+			datnameLabel, mode.String, locktype.String, grantedLabel,
+		)
+	}
+	return rows.Err()
 }
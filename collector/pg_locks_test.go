@@ -36,6 +36,12 @@ func TestPGLocksCollector(t *testing.T) {
 
 	mock.ExpectQuery(sanitizeQuery(pgLocksQuery)).WillReturnRows(rows)
 
+	locktypeRows := sqlmock.NewRows([]string{"datname", "mode", "locktype", "granted", "count"}).
+		AddRow("test", "accessexclusivelock", "relation", true, 1).
+		AddRow("test", "accessexclusivelock", "relation", false, 3).
+		AddRow(nil, "exclusivelock", "transactionid", true, 12)
+	mock.ExpectQuery(sanitizeQuery(pgLocksByLocktypeQuery)).WillReturnRows(locktypeRows)
+
 	ch := make(chan prometheus.Metric)
 	go func() {
 		defer close(ch)
@@ -47,6 +53,9 @@ func TestPGLocksCollector(t *testing.T) {
 
 	expected := []MetricResult{
 		{labels: labelMap{"datname": "test", "mode": "exclusivelock"}, value: 42, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "test", "mode": "accessexclusivelock", "locktype": "relation", "granted": "true"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "test", "mode": "accessexclusivelock", "locktype": "relation", "granted": "false"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "unknown", "mode": "exclusivelock", "locktype": "transactionid", "granted": "true"}, value: 12, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range expected {
@@ -58,3 +67,48 @@ func TestPGLocksCollector(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestPGLocksCollectorDatabaseFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	rows := sqlmock.NewRows([]string{"datname", "mode", "count"}).
+		AddRow("test", "exclusivelock", 42).
+		AddRow("tenant_1", "exclusivelock", 7)
+	mock.ExpectQuery(sanitizeQuery(pgLocksQuery)).WillReturnRows(rows)
+
+	locktypeRows := sqlmock.NewRows([]string{"datname", "mode", "locktype", "granted", "count"}).
+		AddRow("tenant_1", "accessexclusivelock", "relation", true, 1).
+		AddRow(nil, "exclusivelock", "transactionid", true, 12)
+	mock.ExpectQuery(sanitizeQuery(pgLocksByLocktypeQuery)).WillReturnRows(locktypeRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGLocksCollector{databases: newDatabaseFilter(nil, []string{"tenant_1"})}
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGLocksCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "test", "mode": "exclusivelock"}, value: 42, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"datname": "unknown", "mode": "exclusivelock", "locktype": "transactionid", "granted": "true"}, value: 12, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Excluded databases are not reported", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
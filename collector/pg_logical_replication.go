@@ -0,0 +1,116 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const logicalReplicationSubsystem = "logical_replication"
+
+func init() {
+	registerCollector(logicalReplicationSubsystem, defaultDisabled, NewPGLogicalReplicationCollector)
+}
+
+type PGLogicalReplicationCollector struct {
+	log log.Logger
+}
+
+func NewPGLogicalReplicationCollector(config collectorConfig) (Collector, error) {
+	return &PGLogicalReplicationCollector{log: config.logger}, nil
+}
+
+var (
+	logicalReplicationWorkers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logicalReplicationSubsystem, "workers"),
+		"Number of running logical replication workers, including table synchronization workers",
+		[]string{},
+		prometheus.Labels{},
+	)
+	logicalReplicationMaxWorkers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logicalReplicationSubsystem, "max_workers"),
+		"Value of the max_logical_replication_workers setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+	logicalReplicationMaxSyncWorkersPerSubscription = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logicalReplicationSubsystem, "max_sync_workers_per_subscription"),
+		"Value of the max_sync_workers_per_subscription setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	// The launcher is excluded; it does not take a worker slot.
+	logicalReplicationWorkersQuery = `
+	SELECT
+		(SELECT count(*) FROM pg_catalog.pg_stat_activity
+			WHERE backend_type LIKE 'logical replication%worker') AS workers,
+		current_setting('max_logical_replication_workers')::int AS max_workers,
+		current_setting('max_sync_workers_per_subscription')::int AS max_sync_workers_per_subscription
+	`
+)
+
+func (c *PGLogicalReplicationCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// Logical replication and pg_stat_activity.backend_type were added in PostgreSQL 10
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		level.Debug(c.log).Log("msg", "Logical replication is not available before PostgreSQL 10")
+		return nil
+	}
+
+	db := instance.getDB()
+	row := db.QueryRowContext(ctx,
+		logicalReplicationWorkersQuery)
+
+	var workers, maxWorkers, maxSyncWorkers sql.NullInt64
+	if err := row.Scan(&workers, &maxWorkers, &maxSyncWorkers); err != nil {
+		return err
+	}
+
+	workersMetric := 0.0
+	if workers.Valid {
+		workersMetric = float64(workers.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		logicalReplicationWorkers,
+		prometheus.GaugeValue,
+		workersMetric,
+	)
+
+	maxWorkersMetric := 0.0
+	if maxWorkers.Valid {
+		maxWorkersMetric = float64(maxWorkers.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		logicalReplicationMaxWorkers,
+		prometheus.GaugeValue,
+		maxWorkersMetric,
+	)
+
+	maxSyncWorkersMetric := 0.0
+	if maxSyncWorkers.Valid {
+		maxSyncWorkersMetric = float64(maxSyncWorkers.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		logicalReplicationMaxSyncWorkersPerSubscription,
+		prometheus.GaugeValue,
+		maxSyncWorkersMetric,
+	)
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const partitionsSubsystem = "partitions"
+
+func init() {
+	registerCollector(partitionsSubsystem, defaultDisabled, NewPGPartitionsCollector)
+}
+
+type PGPartitionsCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGPartitionsCollector(config collectorConfig) (Collector, error) {
+	return &PGPartitionsCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	partitionsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, partitionsSubsystem, "total"),
+		"Number of direct partitions of the partitioned table",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
+
+	partitionsQuery = `
+	SELECT
+		current_database() AS datname,
+		n.nspname AS schemaname,
+		c.relname,
+		count(i.inhrelid) AS partitions
+	FROM pg_catalog.pg_partitioned_table p
+	JOIN pg_catalog.pg_class c ON c.oid = p.partrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_inherits i ON i.inhparent = p.partrelid
+	GROUP BY n.nspname, c.relname
+	`
+)
+
+func (c *PGPartitionsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// Declarative partitioning was added in PostgreSQL 10
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_partitioned_table is not available before PostgreSQL 10")
+		return nil
+	}
+
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx,
+		partitionsQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, schemaname, relname sql.NullString
+		var partitions sql.NullInt64
+
+		if err := rows.Scan(&datname, &schemaname, &relname, &partitions); err != nil {
+			return err
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		schemanameLabel := "unknown"
+		if schemaname.Valid {
+			schemanameLabel = schemaname.String
+		}
+		relnameLabel := "unknown"
+		if relname.Valid {
+			relnameLabel = relname.String
+		}
+
+		partitionsMetric := 0.0
+		if partitions.Valid {
+			partitionsMetric = float64(partitions.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			partitionsTotal,
+			prometheus.GaugeValue,
+			partitionsMetric,
+			datnameLabel, schemanameLabel, relnameLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
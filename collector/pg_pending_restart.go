@@ -0,0 +1,102 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const pendingRestartSubsystem = "pending_restart"
+
+func init() {
+	registerCollector(pendingRestartSubsystem, defaultEnabled, NewPGPendingRestartCollector)
+}
+
+type PGPendingRestartCollector struct {
+	log log.Logger
+}
+
+func NewPGPendingRestartCollector(config collectorConfig) (Collector, error) {
+	return &PGPendingRestartCollector{log: config.logger}, nil
+}
+
+var (
+	settingsPendingRestart = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "settings", "pending_restart"),
+		"Number of settings that have been changed in the configuration files but need a restart to take effect",
+		[]string{},
+		prometheus.Labels{},
+	)
+	settingsPendingRestartInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "settings", "pending_restart_info"),
+		"Set to 1 for each setting that has been changed in the configuration files but needs a restart to take effect",
+		[]string{"name"},
+		prometheus.Labels{},
+	)
+
+	settingsPendingRestartQuery = `SELECT name FROM pg_settings WHERE pending_restart`
+)
+
+func (c *PGPendingRestartCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pending_restart was added to pg_settings in PostgreSQL 9.5
+	if instance.version.LT(semver.MustParse("9.5.0")) {
+		level.Debug(c.log).Log("msg", "pg_settings.pending_restart is not available before PostgreSQL 9.5")
+		return nil
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		settingsPendingRestartQuery,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name sql.NullString
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if !name.Valid {
+			continue
+		}
+		names = append(names, name.String)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		settingsPendingRestart,
+		prometheus.GaugeValue,
+		float64(len(names)),
+	)
+	for _, name := range names {
+		ch <- prometheus.MustNewConstMetric(
+			settingsPendingRestartInfo,
+			prometheus.GaugeValue,
+			1,
+			name,
+		)
+	}
+	return nil
+}
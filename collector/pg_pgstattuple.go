@@ -0,0 +1,226 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const pgstattupleSubsystem = "pgstattuple"
+
+func init() {
+	// pgstattuple() and pgstatindex() read every page of the relations they
+	// are given, so this collector is disabled by default and should only
+	// be enabled for a few relations or with a long scrape interval.
+	registerCollector(pgstattupleSubsystem, defaultDisabled, NewPGStattupleCollector)
+}
+
+var (
+	pgstattupleRelations = kingpin.Flag("collector.pgstattuple.relations", "Comma-separated list of tables to inspect with pgstattuple, as [schema.]name. WARNING: every listed table and its indexes are read in full on each scrape. Their btree indexes are inspected as well. When empty, the largest tables and indexes are inspected.").Default("").String()
+	pgstattupleLimit     = kingpin.Flag("collector.pgstattuple.limit", "Number of largest tables and largest indexes to inspect when --collector.pgstattuple.relations is empty. WARNING: each of them is read in full on every scrape.").Default("5").Int()
+)
+
+// PGStattupleCollector exposes the exact dead tuple share of tables and the
+// leaf fragmentation of btree indexes using the pgstattuple extension. Unlike
+// estimates based on statistics these are accurate, at the cost of a full
+// scan of every inspected relation on each scrape.
+type PGStattupleCollector struct {
+	log       log.Logger
+	databases databaseFilter
+	relations []string
+	limit     int
+}
+
+func NewPGStattupleCollector(config collectorConfig) (Collector, error) {
+	return &PGStattupleCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		relations: splitList(*pgstattupleRelations),
+		limit:     *pgstattupleLimit,
+	}, nil
+}
+
+var (
+	pgstattupleDeadTuplePercent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "relation", "dead_tuple_percent"),
+		"Percentage of the table taken by dead tuples, as measured by pgstattuple",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
+	pgstattupleLeafFragmentation = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "index", "leaf_fragmentation"),
+		"Percentage of btree leaf pages that are out of physical order, as measured by pgstatindex",
+		[]string{"datname", "schemaname", "relname", "indexrelname"},
+		prometheus.Labels{},
+	)
+
+	pgstattupleExtensionQuery = `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pgstattuple');`
+
+	// Relations that do not exist are ignored by to_regclass.
+	pgstattupleTablesQueryConfigured  = pgstattupleTablesQuery(`AND c.oid IN (SELECT to_regclass(r) FROM unnest($1::text[]) r)`)
+	pgstattupleTablesQueryLargest     = pgstattupleTablesQuery(`AND n.nspname NOT IN ('pg_catalog', 'information_schema') ORDER BY pg_relation_size(c.oid) DESC LIMIT $1`)
+	pgstattupleIndexesQueryConfigured = pgstattupleIndexesQuery(`AND (i.indrelid IN (SELECT to_regclass(r) FROM unnest($1::text[]) r) OR c.oid IN (SELECT to_regclass(r) FROM unnest($1::text[]) r))`)
+	pgstattupleIndexesQueryLargest    = pgstattupleIndexesQuery(`AND n.nspname NOT IN ('pg_catalog', 'information_schema') ORDER BY pg_relation_size(c.oid) DESC LIMIT $1`)
+)
+
+// pgstattupleTablesQuery returns the query running pgstattuple() on the
+// tables picked by selection, which is appended to the WHERE clause of the
+// pg_class scan. Temporary tables are skipped: those of other sessions cannot
+// be read and fail the whole query.
+func pgstattupleTablesQuery(selection string) string {
+	return fmt.Sprintf(`
+	SELECT
+		current_database() AS datname,
+		n.nspname AS schemaname,
+		c.relname,
+		s.dead_tuple_percent
+	FROM (
+		SELECT c.oid, c.relnamespace, c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'm')
+		AND c.relpersistence <> 't'
+		%s
+	) c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL pgstattuple(c.oid) s
+	`, selection)
+}
+
+// pgstattupleIndexesQuery returns the query running pgstatindex() on the
+// btree indexes picked by selection. Partitioned indexes (relkind 'I') have
+// no storage and, like temporary indexes, make pgstatindex() fail.
+func pgstattupleIndexesQuery(selection string) string {
+	return fmt.Sprintf(`
+	SELECT
+		current_database() AS datname,
+		n.nspname AS schemaname,
+		t.relname,
+		c.relname AS indexrelname,
+		s.leaf_fragmentation
+	FROM (
+		SELECT c.oid, c.relnamespace, c.relname, i.indrelid
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_index i ON i.indexrelid = c.oid
+		JOIN pg_catalog.pg_am a ON a.oid = c.relam
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE a.amname = 'btree'
+		AND c.relkind = 'i'
+		AND c.relpersistence <> 't'
+		%s
+	) c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_class t ON t.oid = c.indrelid
+	CROSS JOIN LATERAL pgstatindex(c.oid) s
+	`, selection)
+}
+
+func (c *PGStattupleCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pgstatindex(regclass) was added in PostgreSQL 9.6
+	if instance.version.LT(semver.MustParse("9.6.0")) {
+		level.Debug(c.log).Log("msg", "pgstattuple collector is not available on PostgreSQL < 9.6, skipping")
+		return nil
+	}
+
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	var extensionInstalled bool
+	if err := db.QueryRowContext(ctx, pgstattupleExtensionQuery).Scan(&extensionInstalled); err != nil {
+		return err
+	}
+	if !extensionInstalled {
+		level.Debug(c.log).Log("msg", "pgstattuple extension is not installed")
+		return nil
+	}
+
+	tablesQuery, indexesQuery := pgstattupleTablesQueryLargest, pgstattupleIndexesQueryLargest
+	var arg any = c.limit
+	if len(c.relations) > 0 {
+		tablesQuery, indexesQuery = pgstattupleTablesQueryConfigured, pgstattupleIndexesQueryConfigured
+		arg = pq.Array(c.relations)
+	}
+
+	if err := c.updateTables(ctx, db, tablesQuery, arg, ch); err != nil {
+		return err
+	}
+	return c.updateIndexes(ctx, db, indexesQuery, arg, ch)
+}
+
+func (c *PGStattupleCollector) updateTables(ctx context.Context, db *sql.DB, query string, arg any, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, schemaname, relname sql.NullString
+		var deadTuplePercent sql.NullFloat64
+		if err := rows.Scan(&datname, &schemaname, &relname, &deadTuplePercent); err != nil {
+			return err
+		}
+		if !deadTuplePercent.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			pgstattupleDeadTuplePercent,
+			prometheus.GaugeValue,
+			deadTuplePercent.Float64,
+			datname.String, schemaname.String, relname.String,
+		)
+	}
+	return rows.Err()
+}
+
+func (c *PGStattupleCollector) updateIndexes(ctx context.Context, db *sql.DB, query string, arg any, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, schemaname, relname, indexrelname sql.NullString
+		var leafFragmentation sql.NullFloat64
+		if err := rows.Scan(&datname, &schemaname, &relname, &indexrelname, &leafFragmentation); err != nil {
+			return err
+		}
+		if !leafFragmentation.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			pgstattupleLeafFragmentation,
+			prometheus.GaugeValue,
+			leafFragmentation.Float64,
+			datname.String, schemaname.String, relname.String, indexrelname.String,
+		)
+	}
+	return rows.Err()
+}
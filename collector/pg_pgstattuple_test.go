@@ -0,0 +1,204 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGStattupleCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(pgstattupleExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	tableRows := sqlmock.NewRows([]string{"datname", "schemaname", "relname", "dead_tuple_percent"}).
+		AddRow("postgres", "public", "orders", 12.5).
+		AddRow("postgres", "public", "customers", 0.75)
+	mock.ExpectQuery(sanitizeQuery(pgstattupleTablesQueryLargest)).WithArgs(5).WillReturnRows(tableRows)
+	indexRows := sqlmock.NewRows([]string{"datname", "schemaname", "relname", "indexrelname", "leaf_fragmentation"}).
+		AddRow("postgres", "public", "orders", "orders_pkey", 33.3).
+		AddRow("postgres", "public", "orders", "orders_empty_idx", nil)
+	mock.ExpectQuery(sanitizeQuery(pgstattupleIndexesQueryLargest)).WithArgs(5).WillReturnRows(indexRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStattupleCollector{log: log.NewNopLogger(), limit: 5}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStattupleCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "postgres", "schemaname": "public", "relname": "orders"}, metricType: dto.MetricType_GAUGE, value: 12.5},
+		{labels: labelMap{"datname": "postgres", "schemaname": "public", "relname": "customers"}, metricType: dto.MetricType_GAUGE, value: 0.75},
+		{labels: labelMap{"datname": "postgres", "schemaname": "public", "relname": "orders", "indexrelname": "orders_pkey"}, metricType: dto.MetricType_GAUGE, value: 33.3},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStattupleCollectorConfiguredRelations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(pgstattupleExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	tableRows := sqlmock.NewRows([]string{"datname", "schemaname", "relname", "dead_tuple_percent"}).
+		AddRow("postgres", "sales", "orders", 4.0)
+	mock.ExpectQuery(sanitizeQuery(pgstattupleTablesQueryConfigured)).WithArgs(`{"sales.orders"}`).WillReturnRows(tableRows)
+	indexRows := sqlmock.NewRows([]string{"datname", "schemaname", "relname", "indexrelname", "leaf_fragmentation"})
+	mock.ExpectQuery(sanitizeQuery(pgstattupleIndexesQueryConfigured)).WithArgs(`{"sales.orders"}`).WillReturnRows(indexRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStattupleCollector{log: log.NewNopLogger(), relations: []string{"sales.orders"}, limit: 5}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStattupleCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "postgres", "schemaname": "sales", "relname": "orders"}, metricType: dto.MetricType_GAUGE, value: 4.0},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStattupleCollectorNoExtension(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(pgstattupleExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStattupleCollector{log: log.NewNopLogger(), limit: 5}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStattupleCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No metrics without the pgstattuple extension", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStattupleCollectorExcludedDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(currentDatabaseQuery)).WillReturnRows(sqlmock.NewRows([]string{"current_database"}).AddRow("postgres"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStattupleCollector{
+			log:       log.NewNopLogger(),
+			limit:     5,
+			databases: newDatabaseFilter(nil, []string{"postgres"}),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStattupleCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("An excluded database is not scanned", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStattupleQueriesSkipUnreadableRelations(t *testing.T) {
+	for name, query := range map[string]string{
+		"tables configured":  pgstattupleTablesQueryConfigured,
+		"tables largest":     pgstattupleTablesQueryLargest,
+		"indexes configured": pgstattupleIndexesQueryConfigured,
+		"indexes largest":    pgstattupleIndexesQueryLargest,
+	} {
+		// Temporary relations of other sessions cannot be read.
+		if !strings.Contains(query, "c.relpersistence <> 't'") {
+			t.Errorf("%s query does not skip temporary relations", name)
+		}
+	}
+	for name, query := range map[string]string{
+		"indexes configured": pgstattupleIndexesQueryConfigured,
+		"indexes largest":    pgstattupleIndexesQueryLargest,
+	} {
+		// Partitioned indexes (relkind 'I') have no storage.
+		if !strings.Contains(query, "c.relkind = 'i'") {
+			t.Errorf("%s query does not skip partitioned indexes", name)
+		}
+	}
+}
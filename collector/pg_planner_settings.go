@@ -0,0 +1,121 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const plannerSettingsSubsystem = "planner_settings"
+
+func init() {
+	registerCollector(plannerSettingsSubsystem, defaultDisabled, NewPGPlannerSettingsCollector)
+}
+
+type PGPlannerSettingsCollector struct {
+	log log.Logger
+}
+
+func NewPGPlannerSettingsCollector(config collectorConfig) (Collector, error) {
+	return &PGPlannerSettingsCollector{log: config.logger}, nil
+}
+
+var (
+	plannerSettingsEffectiveIOConcurrency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, plannerSettingsSubsystem, "effective_io_concurrency"),
+		"Value of the effective_io_concurrency setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+	plannerSettingsMaintenanceIOConcurrency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, plannerSettingsSubsystem, "maintenance_io_concurrency"),
+		"Value of the maintenance_io_concurrency setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+	plannerSettingsRandomPageCost = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, plannerSettingsSubsystem, "random_page_cost"),
+		"Value of the random_page_cost setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+	plannerSettingsSeqPageCost = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, plannerSettingsSubsystem, "seq_page_cost"),
+		"Value of the seq_page_cost setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	plannerSettingsQuery = `
+	SELECT
+		current_setting('effective_io_concurrency')::float AS effective_io_concurrency,
+		current_setting('maintenance_io_concurrency')::float AS maintenance_io_concurrency,
+		current_setting('random_page_cost')::float AS random_page_cost,
+		current_setting('seq_page_cost')::float AS seq_page_cost
+	`
+
+	plannerSettingsQueryPre13 = `
+	SELECT
+		current_setting('effective_io_concurrency')::float AS effective_io_concurrency,
+		NULL::float AS maintenance_io_concurrency,
+		current_setting('random_page_cost')::float AS random_page_cost,
+		current_setting('seq_page_cost')::float AS seq_page_cost
+	`
+)
+
+func (c *PGPlannerSettingsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+
+	// maintenance_io_concurrency was added in PostgreSQL 13.
+	query := plannerSettingsQuery
+	if instance.version.LT(semver.MustParse("13.0.0")) {
+		query = plannerSettingsQueryPre13
+	}
+
+	var effectiveIOConcurrency, maintenanceIOConcurrency, randomPageCost, seqPageCost sql.NullFloat64
+	err := db.QueryRowContext(ctx, query).Scan(
+		&effectiveIOConcurrency,
+		&maintenanceIOConcurrency,
+		&randomPageCost,
+		&seqPageCost,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range []struct {
+		desc  *prometheus.Desc
+		value sql.NullFloat64
+	}{
+		{plannerSettingsEffectiveIOConcurrency, effectiveIOConcurrency},
+		{plannerSettingsMaintenanceIOConcurrency, maintenanceIOConcurrency},
+		{plannerSettingsRandomPageCost, randomPageCost},
+		{plannerSettingsSeqPageCost, seqPageCost},
+	} {
+		if !setting.value.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			setting.desc,
+			prometheus.GaugeValue,
+			setting.value.Float64,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const preparedTransactionsSubsystem = "prepared_transactions"
+
+func init() {
+	registerCollector(preparedTransactionsSubsystem, defaultDisabled, NewPGPreparedTransactionsCollector)
+}
+
+type PGPreparedTransactionsCollector struct {
+	log log.Logger
+}
+
+func NewPGPreparedTransactionsCollector(config collectorConfig) (Collector, error) {
+	return &PGPreparedTransactionsCollector{log: config.logger}, nil
+}
+
+var (
+	preparedTransactionsCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedTransactionsSubsystem, "count"),
+		"Number of transactions currently prepared for two-phase commit",
+		[]string{},
+		prometheus.Labels{},
+	)
+	preparedTransactionsMax = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedTransactionsSubsystem, "max"),
+		"Value of the max_prepared_transactions setting",
+		[]string{},
+		prometheus.Labels{},
+	)
+	preparedTransactionsUnexpected = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedTransactionsSubsystem, "unexpected"),
+		"Whether prepared transactions exist although max_prepared_transactions is 0 (1 = yes, 0 = no)",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	preparedTransactionsOldestXIDAge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedTransactionsSubsystem, "oldest_xid_age"),
+		"Age in transactions of the oldest prepared transaction, which holds back vacuum and wraparound protection",
+		[]string{},
+		prometheus.Labels{},
+	)
+	preparedTransactionsOldestAge = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedTransactionsSubsystem, "oldest_age_seconds"),
+		"Time in seconds since the oldest prepared transaction was prepared",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	preparedTransactionsQuery = `
+	SELECT
+		current_setting('max_prepared_transactions')::int AS max_prepared_transactions,
+		count(*) AS prepared_transactions,
+		COALESCE(max(age(transaction)), 0) AS oldest_xid_age,
+		COALESCE(EXTRACT(EPOCH FROM now() - min(prepared)), 0) AS oldest_age_seconds
+	FROM pg_catalog.pg_prepared_xacts
+	`
+)
+
+func (c *PGPreparedTransactionsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	row := db.QueryRowContext(ctx,
+		preparedTransactionsQuery)
+
+	var maxPrepared, prepared, oldestXIDAge sql.NullInt64
+	var oldestAge sql.NullFloat64
+	if err := row.Scan(&maxPrepared, &prepared, &oldestXIDAge, &oldestAge); err != nil {
+		return err
+	}
+
+	preparedMetric := 0.0
+	if prepared.Valid {
+		preparedMetric = float64(prepared.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		preparedTransactionsCount,
+		prometheus.GaugeValue,
+		preparedMetric,
+	)
+
+	maxPreparedMetric := 0.0
+	if maxPrepared.Valid {
+		maxPreparedMetric = float64(maxPrepared.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		preparedTransactionsMax,
+		prometheus.GaugeValue,
+		maxPreparedMetric,
+	)
+
+	unexpectedMetric := 0.0
+	if maxPreparedMetric == 0 && preparedMetric > 0 {
+		unexpectedMetric = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(
+		preparedTransactionsUnexpected,
+		prometheus.GaugeValue,
+		unexpectedMetric,
+	)
+
+	oldestXIDAgeMetric := 0.0
+	if oldestXIDAge.Valid {
+		oldestXIDAgeMetric = float64(oldestXIDAge.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		preparedTransactionsOldestXIDAge,
+		prometheus.GaugeValue,
+		oldestXIDAgeMetric,
+	)
+
+	oldestAgeMetric := 0.0
+	if oldestAge.Valid {
+		oldestAgeMetric = oldestAge.Float64
+	}
+	ch <- prometheus.MustNewConstMetric(
+		preparedTransactionsOldestAge,
+		prometheus.GaugeValue,
+		oldestAgeMetric,
+	)
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const primaryKeySubsystem = "primary_key"
+
+func init() {
+	registerCollector(primaryKeySubsystem, defaultDisabled, NewPGPrimaryKeyCollector)
+}
+
+var primaryKeyPerTable = kingpin.Flag("collector.primary_key.per-table", "Also report each table without a primary key as its own series.").Default("false").Bool()
+
+type PGPrimaryKeyCollector struct {
+	log       log.Logger
+	databases databaseFilter
+	perTable  bool
+}
+
+func NewPGPrimaryKeyCollector(config collectorConfig) (Collector, error) {
+	return &PGPrimaryKeyCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		perTable:  *primaryKeyPerTable,
+	}, nil
+}
+
+var (
+	tablesWithoutPrimaryKey = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "tables", "without_primary_key"),
+		"Number of tables in the database without a primary key",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
+	tableWithoutPrimaryKey = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "table", "without_primary_key"),
+		"Set to 1 for each table without a primary key",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
+
+	tablesWithoutPrimaryKeyPredicate = `
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind IN ('r', 'p')
+	AND c.relpersistence <> 't'
+	AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+	AND n.nspname NOT LIKE 'pg_toast%'
+	AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_constraint con
+		WHERE con.conrelid = c.oid AND con.contype = 'p'
+	)`
+
+	tablesWithoutPrimaryKeyCountQuery = `SELECT current_database() AS datname, count(*)` + tablesWithoutPrimaryKeyPredicate
+
+	tablesWithoutPrimaryKeyQuery = `SELECT current_database() AS datname, n.nspname AS schemaname, c.relname` + tablesWithoutPrimaryKeyPredicate
+)
+
+func (c *PGPrimaryKeyCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	var datname sql.NullString
+	var count sql.NullInt64
+	err = db.QueryRowContext(ctx,
+		tablesWithoutPrimaryKeyCountQuery,
+	).Scan(&datname, &count)
+	if err != nil {
+		return err
+	}
+
+	datnameLabel := "unknown"
+	if datname.Valid {
+		datnameLabel = datname.String
+	}
+	countMetric := 0.0
+	if count.Valid {
+		countMetric = float64(count.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		tablesWithoutPrimaryKey,
+		prometheus.GaugeValue,
+		countMetric,
+		datnameLabel,
+	)
+
+	if !c.perTable {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx,
+		tablesWithoutPrimaryKeyQuery,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, schemaname, relname sql.NullString
+		if err := rows.Scan(&datname, &schemaname, &relname); err != nil {
+			return err
+		}
+		if !datname.Valid || !schemaname.Valid || !relname.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tableWithoutPrimaryKey,
+			prometheus.GaugeValue,
+			1,
+			datname.String, schemaname.String, relname.String,
+		)
+	}
+	return rows.Err()
+}
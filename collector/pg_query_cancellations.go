@@ -0,0 +1,146 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const queryCancellationsSubsystem = "query_cancellations"
+
+func init() {
+	registerCollector(queryCancellationsSubsystem, defaultDisabled, NewPGQueryCancellationsCollector)
+}
+
+// PGQueryCancellationsCollector exposes per database counts of canceled
+// queries. PostgreSQL only counts the queries canceled by recovery conflicts
+// on standbys; there are no SQL-accessible counters for cancellations caused
+// by statement_timeout, lock_timeout or the client. Rolled back transactions
+// and deadlocks are exported next to them as the closest proxies.
+type PGQueryCancellationsCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGQueryCancellationsCollector(config collectorConfig) (Collector, error) {
+	return &PGQueryCancellationsCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	queryCancellationsRecoveryConflicts = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, queryCancellationsSubsystem, "recovery_conflicts_total"),
+		"Number of queries in this database that have been canceled due to conflicts with recovery, by reason",
+		[]string{"datid", "datname", "reason"},
+		prometheus.Labels{},
+	)
+	queryCancellationsXactRollback = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, queryCancellationsSubsystem, "xact_rollback_total"),
+		"Number of transactions in this database that have been rolled back. Includes queries canceled by statement_timeout, lock_timeout or the client, which PostgreSQL does not count separately",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	queryCancellationsDeadlocks = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, queryCancellationsSubsystem, "deadlocks_total"),
+		"Number of deadlocks detected in this database. Each one cancels a query",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+)
+
+// queryCancellationsQuery joins the conflict counters to pg_stat_database.
+// USING merges the datname columns, so where can refer to it unqualified.
+func queryCancellationsQuery(conflicts []string, where string) string {
+	return fmt.Sprintf(
+		"SELECT datid, datname, xact_rollback, deadlocks, %s FROM pg_stat_database JOIN pg_stat_database_conflicts USING (datid, datname)%s;",
+		strings.Join(conflicts, ","), where,
+	)
+}
+
+func (c *PGQueryCancellationsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	conflicts := []string{
+		"confl_tablespace",
+		"confl_lock",
+		"confl_snapshot",
+		"confl_bufferpin",
+		"confl_deadlock",
+	}
+	// confl_active_logicalslot was added in PostgreSQL 16
+	if instance.version.GE(semver.MustParse("16.0.0")) {
+		conflicts = append(conflicts, "confl_active_logicalslot")
+	}
+
+	db := instance.getDB()
+	where, args := c.databases.where()
+	rows, err := db.QueryContext(ctx,
+		queryCancellationsQuery(conflicts, where),
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datid, datname sql.NullString
+		var xactRollback, deadlocks sql.NullFloat64
+		values := make([]sql.NullFloat64, len(conflicts))
+
+		r := []any{&datid, &datname, &xactRollback, &deadlocks}
+		for i := range values {
+			r = append(r, &values[i])
+		}
+		if err := rows.Scan(r...); err != nil {
+			return err
+		}
+
+		if !datid.Valid || !datname.Valid {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			queryCancellationsXactRollback,
+			prometheus.CounterValue,
+			xactRollback.Float64,
+			datid.String, datname.String,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			queryCancellationsDeadlocks,
+			prometheus.CounterValue,
+			deadlocks.Float64,
+			datid.String, datname.String,
+		)
+		for i, conflict := range conflicts {
+			ch <- prometheus.MustNewConstMetric(
+				queryCancellationsRecoveryConflicts,
+				prometheus.CounterValue,
+				values[i].Float64,
+				datid.String, datname.String, strings.TrimPrefix(conflict, "confl_"),
+			)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGQueryCancellationsCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("15.0.0")}
+
+	conflicts := []string{"confl_tablespace", "confl_lock", "confl_snapshot", "confl_bufferpin", "confl_deadlock"}
+	rows := sqlmock.NewRows(append([]string{"datid", "datname", "xact_rollback", "deadlocks"}, conflicts...)).
+		AddRow("5", "postgres", 12, 1, 0, 3, 7, nil, 1).
+		AddRow(nil, nil, 0, 0, 0, 0, 0, 0, 0)
+	mock.ExpectQuery(sanitizeQuery(queryCancellationsQuery(conflicts, ""))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGQueryCancellationsCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGQueryCancellationsCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datid": "5", "datname": "postgres"}, metricType: dto.MetricType_COUNTER, value: 12},
+		{labels: labelMap{"datid": "5", "datname": "postgres"}, metricType: dto.MetricType_COUNTER, value: 1},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "tablespace"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "lock"}, metricType: dto.MetricType_COUNTER, value: 3},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "snapshot"}, metricType: dto.MetricType_COUNTER, value: 7},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "bufferpin"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "deadlock"}, metricType: dto.MetricType_COUNTER, value: 1},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGQueryCancellationsCollectorPG16(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	conflicts := []string{"confl_tablespace", "confl_lock", "confl_snapshot", "confl_bufferpin", "confl_deadlock", "confl_active_logicalslot"}
+	rows := sqlmock.NewRows(append([]string{"datid", "datname", "xact_rollback", "deadlocks"}, conflicts...)).
+		AddRow("5", "postgres", 4, 0, 0, 0, 0, 0, 0, 2)
+	mock.ExpectQuery(sanitizeQuery(queryCancellationsQuery(conflicts, ""))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGQueryCancellationsCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGQueryCancellationsCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datid": "5", "datname": "postgres"}, metricType: dto.MetricType_COUNTER, value: 4},
+		{labels: labelMap{"datid": "5", "datname": "postgres"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "tablespace"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "lock"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "snapshot"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "bufferpin"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "deadlock"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "active_logicalslot"}, metricType: dto.MetricType_COUNTER, value: 2},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGQueryCancellationsCollectorDatabaseFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("15.0.0")}
+
+	c := PGQueryCancellationsCollector{
+		databases: newDatabaseFilter(nil, []string{"tenant_1", "tenant_2"}),
+	}
+	where, _ := c.databases.where()
+
+	conflicts := []string{"confl_tablespace", "confl_lock", "confl_snapshot", "confl_bufferpin", "confl_deadlock"}
+	rows := sqlmock.NewRows(append([]string{"datid", "datname", "xact_rollback", "deadlocks"}, conflicts...)).
+		AddRow("5", "postgres", 12, 1, 0, 3, 7, 0, 1)
+	mock.ExpectQuery(sanitizeQuery(queryCancellationsQuery(conflicts, where))).
+		WithArgs(`{"tenant_1","tenant_2"}`).
+		WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGQueryCancellationsCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datid": "5", "datname": "postgres"}, metricType: dto.MetricType_COUNTER, value: 12},
+		{labels: labelMap{"datid": "5", "datname": "postgres"}, metricType: dto.MetricType_COUNTER, value: 1},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "tablespace"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "lock"}, metricType: dto.MetricType_COUNTER, value: 3},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "snapshot"}, metricType: dto.MetricType_COUNTER, value: 7},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "bufferpin"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"datid": "5", "datname": "postgres", "reason": "deadlock"}, metricType: dto.MetricType_COUNTER, value: 1},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
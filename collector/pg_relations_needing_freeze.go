@@ -0,0 +1,96 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const relationsNeedingFreezeSubsystem = "relations_needing_freeze"
+
+func init() {
+	// Scans pg_class on every scrape, so it is disabled by default.
+	registerCollector(relationsNeedingFreezeSubsystem, defaultDisabled, NewPGRelationsNeedingFreezeCollector)
+}
+
+// PGRelationsNeedingFreezeCollector counts the relations of the current
+// database that the next vacuum will scan aggressively, which predicts the
+// I/O spikes of upcoming freeze vacuums.
+type PGRelationsNeedingFreezeCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGRelationsNeedingFreezeCollector(config collectorConfig) (Collector, error) {
+	return &PGRelationsNeedingFreezeCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	relationsNeedingFreeze = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "relations_needing_freeze"),
+		"Number of relations whose relfrozenxid age exceeds vacuum_freeze_table_age, so that their next vacuum is aggressive",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
+
+	// VACUUM silently limits vacuum_freeze_table_age to 95% of
+	// autovacuum_freeze_max_age.
+	relationsNeedingFreezeQuery = `
+	SELECT
+		current_database() AS datname,
+		count(*) AS relations
+	FROM pg_catalog.pg_class c
+	WHERE c.relkind IN ('r', 'm', 't')
+		AND age(c.relfrozenxid) > LEAST(
+			current_setting('vacuum_freeze_table_age')::bigint,
+			current_setting('autovacuum_freeze_max_age')::bigint * 0.95
+		)
+	`
+)
+
+func (c *PGRelationsNeedingFreezeCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	var datname sql.NullString
+	var relations sql.NullInt64
+	if err := db.QueryRowContext(ctx, relationsNeedingFreezeQuery).Scan(&datname, &relations); err != nil {
+		return err
+	}
+
+	datnameLabel := "unknown"
+	if datname.Valid {
+		datnameLabel = datname.String
+	}
+	ch <- prometheus.MustNewConstMetric(
+		relationsNeedingFreeze,
+		prometheus.GaugeValue,
+		float64(relations.Int64),
+		datnameLabel,
+	)
+	return nil
+}
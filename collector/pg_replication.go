@@ -15,7 +15,11 @@ package collector
 
 import (
 	"context"
+	"database/sql"
+	"strconv"
 
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -26,10 +30,11 @@ func init() {
 }
 
 type PGReplicationCollector struct {
+	log log.Logger
 }
 
-func NewPGReplicationCollector(collectorConfig) (Collector, error) {
-	return &PGReplicationCollector{}, nil
+func NewPGReplicationCollector(config collectorConfig) (Collector, error) {
+	return &PGReplicationCollector{log: config.logger}, nil
 }
 
 var (
@@ -51,6 +56,15 @@ var (
 		"Indicates if the server is a replica",
 		[]string{}, nil,
 	)
+	pgReplicationStandbySecondsSinceLastMsg = prometheus.NewDesc(
+		prometheus.BuildFQName(
+			namespace,
+			replicationSubsystem,
+			"standby_seconds_since_last_msg",
+		),
+		"Seconds since the last reply message was received from the standby",
+		[]string{"pid", "application_name", "client_addr", "state"}, nil,
+	)
 
 	pgReplicationQuery = `SELECT
 	CASE
@@ -62,6 +76,14 @@ var (
 		WHEN pg_is_in_recovery() THEN 1
 		ELSE 0
 	END as is_replica`
+
+	pgReplicationStandbyQuery = `SELECT
+		pid,
+		application_name,
+		COALESCE(host(client_addr), '') AS client_addr,
+		state,
+		GREATEST(0, EXTRACT(EPOCH FROM (now() - reply_time))) AS seconds_since_last_msg
+	FROM pg_stat_replication`
 )
 
 func (c *PGReplicationCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
@@ -84,5 +106,53 @@ func (c *PGReplicationCollector) Update(ctx context.Context, instance *instance,
 		pgReplicationIsReplica,
 		prometheus.GaugeValue, float64(isReplica),
 	)
-	return nil
+
+	// reply_time was added to pg_stat_replication in PostgreSQL 12
+	if instance.version.LT(semver.MustParse("12.0.0")) {
+		return nil
+	}
+	return c.updateStandbys(ctx, db, ch)
+}
+
+// updateStandbys reports how long each connected standby has been silent, so
+// that a walsender which is still connected but no longer receiving replies
+// can be told apart from one that is keeping up.
+func (c *PGReplicationCollector) updateStandbys(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		pgReplicationStandbyQuery,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pid sql.NullInt64
+		var applicationName, clientAddr, state sql.NullString
+		var secondsSinceLastMsg sql.NullFloat64
+		if err := rows.Scan(&pid, &applicationName, &clientAddr, &state, &secondsSinceLastMsg); err != nil {
+			return err
+		}
+		if !secondsSinceLastMsg.Valid {
+			continue
+		}
+
+		// The walsender pid tells apart standbys sharing an
+		// application_name and client address. The labels are built like
+		// those of pg_stat_replication, so that the series can be joined.
+		pidLabel := "unknown"
+		if pid.Valid {
+			pidLabel = strconv.FormatInt(pid.Int64, 10)
+		}
+		stateLabel := "unknown"
+		if state.Valid {
+			stateLabel = state.String
+		}
+		ch <- prometheus.MustNewConstMetric(
+			pgReplicationStandbySecondsSinceLastMsg,
+			prometheus.GaugeValue, secondsSinceLastMsg.Float64,
+			pidLabel, applicationName.String, clientAddr.String, stateLabel,
+		)
+	}
+	return rows.Err()
 }
@@ -0,0 +1,152 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const replicationOriginSubsystem = "replication_origin"
+
+func init() {
+	registerCollector(replicationOriginSubsystem, defaultDisabled, NewPGReplicationOriginCollector)
+}
+
+type PGReplicationOriginCollector struct {
+	log log.Logger
+}
+
+func NewPGReplicationOriginCollector(config collectorConfig) (Collector, error) {
+	return &PGReplicationOriginCollector{log: config.logger}, nil
+}
+
+var (
+	replicationOriginRemoteLSN = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, replicationOriginSubsystem, "remote_lsn"),
+		"Position in the origin's WAL of the last transaction replayed from it",
+		[]string{"origin"},
+		prometheus.Labels{},
+	)
+	replicationOriginLocalLSN = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, replicationOriginSubsystem, "local_lsn"),
+		"Position in the local WAL of the commit record of the last transaction replayed from the origin",
+		[]string{"origin"},
+		prometheus.Labels{},
+	)
+	replicationOriginLagBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, replicationOriginSubsystem, "lag_bytes"),
+		"Local WAL written since the last transaction replayed from the origin, in bytes",
+		[]string{"origin"},
+		prometheus.Labels{},
+	)
+
+	replicationOriginQuery = `
+	SELECT
+		external_id AS origin,
+		remote_lsn - '0/0' AS remote_lsn,
+		local_lsn - '0/0' AS local_lsn,
+		CASE WHEN pg_is_in_recovery() THEN NULL
+		ELSE pg_current_wal_lsn() - local_lsn
+		END AS lag_bytes
+	FROM pg_catalog.pg_replication_origin_status
+	`
+
+	replicationOriginQueryPre10 = `
+	SELECT
+		external_id AS origin,
+		remote_lsn - '0/0' AS remote_lsn,
+		local_lsn - '0/0' AS local_lsn,
+		CASE WHEN pg_is_in_recovery() THEN NULL
+		ELSE pg_current_xlog_location() - local_lsn
+		END AS lag_bytes
+	FROM pg_catalog.pg_replication_origin_status
+	`
+)
+
+func (c *PGReplicationOriginCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// Replication origins were added in PostgreSQL 9.5
+	if instance.version.LT(semver.MustParse("9.5.0")) {
+		level.Debug(c.log).Log("msg", "pg_replication_origin_status is not available before PostgreSQL 9.5")
+		return nil
+	}
+
+	query := replicationOriginQueryPre10
+	if instance.version.GE(semver.MustParse("10.0.0")) {
+		query = replicationOriginQuery
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var origin sql.NullString
+		var remoteLSN, localLSN, lagBytes sql.NullFloat64
+
+		if err := rows.Scan(&origin, &remoteLSN, &localLSN, &lagBytes); err != nil {
+			return err
+		}
+
+		originLabel := "unknown"
+		if origin.Valid {
+			originLabel = origin.String
+		}
+
+		remoteLSNMetric := 0.0
+		if remoteLSN.Valid {
+			remoteLSNMetric = remoteLSN.Float64
+		}
+		ch <- prometheus.MustNewConstMetric(
+			replicationOriginRemoteLSN,
+			prometheus.GaugeValue,
+			remoteLSNMetric,
+			originLabel,
+		)
+
+		localLSNMetric := 0.0
+		if localLSN.Valid {
+			localLSNMetric = localLSN.Float64
+		}
+		ch <- prometheus.MustNewConstMetric(
+			replicationOriginLocalLSN,
+			prometheus.GaugeValue,
+			localLSNMetric,
+			originLabel,
+		)
+
+		if !lagBytes.Valid {
+			level.Debug(c.log).Log("msg", "Skipping lag for replication origin during recovery", "origin", originLabel)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			replicationOriginLagBytes,
+			prometheus.GaugeValue,
+			lagBytes.Float64,
+			originLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGReplicationOriginCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	rows := sqlmock.NewRows([]string{"origin", "remote_lsn", "local_lsn", "lag_bytes"}).
+		AddRow("node_b", 83886080, 50331648, 1024).
+		AddRow("node_c", nil, 50331648, nil)
+	mock.ExpectQuery(sanitizeQuery(replicationOriginQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGReplicationOriginCollector{
+			log: log.With(log.NewNopLogger(), "collector", "replication_origin"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGReplicationOriginCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"origin": "node_b"}, metricType: dto.MetricType_GAUGE, value: 83886080},
+		{labels: labelMap{"origin": "node_b"}, metricType: dto.MetricType_GAUGE, value: 50331648},
+		{labels: labelMap{"origin": "node_b"}, metricType: dto.MetricType_GAUGE, value: 1024},
+		{labels: labelMap{"origin": "node_c"}, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: labelMap{"origin": "node_c"}, metricType: dto.MetricType_GAUGE, value: 50331648},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGReplicationOriginCollectorPre10(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("9.6.0")}
+
+	rows := sqlmock.NewRows([]string{"origin", "remote_lsn", "local_lsn", "lag_bytes"}).
+		AddRow("node_b", 83886080, 50331648, 0)
+	mock.ExpectQuery(sanitizeQuery(replicationOriginQueryPre10)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGReplicationOriginCollector{
+			log: log.With(log.NewNopLogger(), "collector", "replication_origin"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGReplicationOriginCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"origin": "node_b"}, metricType: dto.MetricType_GAUGE, value: 83886080},
+		{labels: labelMap{"origin": "node_b"}, metricType: dto.MetricType_GAUGE, value: 50331648},
+		{labels: labelMap{"origin": "node_b"}, metricType: dto.MetricType_GAUGE, value: 0},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
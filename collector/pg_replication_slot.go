@@ -17,6 +17,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/blang/semver/v4"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -81,6 +82,24 @@ var (
 		"availability of WAL files claimed by this slot",
 		[]string{"slot_name", "slot_type", "wal_status"}, nil,
 	)
+	pgReplicationSlotWalRetained = prometheus.NewDesc(
+		prometheus.BuildFQName(
+			namespace,
+			replicationSlotSubsystem,
+			"wal_retained_bytes",
+		),
+		"number of bytes of WAL between the current WAL position and the oldest WAL still required by this slot",
+		[]string{"slot_name", "slot_type"}, nil,
+	)
+	pgReplicationSlotConsumerInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(
+			namespace,
+			replicationSlotSubsystem,
+			"consumer_info",
+		),
+		"application and client address of the backend consuming an active logical replication slot",
+		[]string{"slot_name", "application_name", "client_addr"}, nil,
+	)
 
 	pgReplicationSlotQuery = `SELECT
 		slot_name,
@@ -93,14 +112,56 @@ var (
 		COALESCE(confirmed_flush_lsn, '0/0') - '0/0' AS confirmed_flush_lsn,
 		active,
 		safe_wal_size,
-		wal_status
+		wal_status,
+		CASE WHEN pg_is_in_recovery() THEN
+		    pg_last_wal_receive_lsn() - restart_lsn
+		ELSE
+		    pg_current_wal_lsn() - restart_lsn
+		END AS wal_retained_bytes
 	FROM pg_replication_slots;`
+
+	// safe_wal_size and wal_status were added in PostgreSQL 13
+	pgReplicationSlotQueryPre13 = `SELECT
+		slot_name,
+		slot_type,
+		CASE WHEN pg_is_in_recovery() THEN
+		    pg_last_wal_receive_lsn() - '0/0'
+		ELSE
+		    pg_current_wal_lsn() - '0/0'
+		END AS current_wal_lsn,
+		COALESCE(confirmed_flush_lsn, '0/0') - '0/0' AS confirmed_flush_lsn,
+		active,
+		NULL::bigint AS safe_wal_size,
+		NULL::text AS wal_status,
+		CASE WHEN pg_is_in_recovery() THEN
+		    pg_last_wal_receive_lsn() - restart_lsn
+		ELSE
+		    pg_current_wal_lsn() - restart_lsn
+		END AS wal_retained_bytes
+	FROM pg_replication_slots;`
+
+	pgReplicationSlotConsumerQuery = `SELECT
+		s.slot_name,
+		a.application_name,
+		COALESCE(host(a.client_addr), '') AS client_addr
+	FROM pg_replication_slots s
+	JOIN pg_stat_activity a ON a.pid = s.active_pid
+	WHERE s.slot_type = 'logical';`
 )
 
 func (PGReplicationSlotCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
 	db := instance.getDB()
+	if err := updateReplicationSlotConsumers(ctx, db, ch); err != nil {
+		return err
+	}
+
+	query := pgReplicationSlotQuery
+	if instance.version.LT(semver.MustParse("13.0.0")) {
+		query = pgReplicationSlotQueryPre13
+	}
+
 	rows, err := db.QueryContext(ctx,
-		pgReplicationSlotQuery)
+		query)
 	if err != nil {
 		return err
 	}
@@ -114,7 +175,8 @@ func (PGReplicationSlotCollector) Update(ctx context.Context, instance *instance
 		var isActive sql.NullBool
 		var safeWalSize sql.NullInt64
 		var walStatus sql.NullString
-		if err := rows.Scan(&slotName, &slotType, &walLSN, &flushLSN, &isActive, &safeWalSize, &walStatus); err != nil {
+		var walRetained sql.NullFloat64
+		if err := rows.Scan(&slotName, &slotType, &walLSN, &flushLSN, &isActive, &safeWalSize, &walStatus, &walRetained); err != nil {
 			return err
 		}
 
@@ -167,6 +229,41 @@ func (PGReplicationSlotCollector) Update(ctx context.Context, instance *instance
 				prometheus.GaugeValue, 1, slotNameLabel, slotTypeLabel, walStatus.String,
 			)
 		}
+
+		// restart_lsn is NULL for slots which have never reserved WAL.
+		if walRetained.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				pgReplicationSlotWalRetained,
+				prometheus.GaugeValue, walRetained.Float64, slotNameLabel, slotTypeLabel,
+			)
+		}
+	}
+	return rows.Err()
+}
+
+// updateReplicationSlotConsumers exposes who is consuming each active logical
+// replication slot. Inactive slots have no active_pid and are left out.
+func updateReplicationSlotConsumers(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		pgReplicationSlotConsumerQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slotName, applicationName, clientAddr sql.NullString
+		if err := rows.Scan(&slotName, &applicationName, &clientAddr); err != nil {
+			return err
+		}
+
+		if !slotName.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			pgReplicationSlotConsumerInfo,
+			prometheus.GaugeValue, 1, slotName.String, applicationName.String, clientAddr.String,
+		)
 	}
 	return rows.Err()
 }
@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
@@ -61,3 +62,54 @@ func TestPgReplicationCollector(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestPgReplicationCollectorStandbys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("12.0.0")}
+
+	columns := []string{"lag", "is_replica"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(0, 0)
+	mock.ExpectQuery(sanitizeQuery(pgReplicationQuery)).WillReturnRows(rows)
+
+	standbyColumns := []string{"pid", "application_name", "client_addr", "state", "seconds_since_last_msg"}
+	standbyRows := sqlmock.NewRows(standbyColumns).
+		AddRow(4242, "walreceiver", "10.0.0.2", "streaming", 0.5).
+		AddRow(4243, "", "", "startup", 120).
+		AddRow(4244, "walreceiver", "10.0.0.3", "catchup", nil).
+		AddRow(4245, "walreceiver", "10.0.0.2", "streaming", 2)
+	mock.ExpectQuery(sanitizeQuery(pgReplicationStandbyQuery)).WillReturnRows(standbyRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGReplicationCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGReplicationCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pid": "4242", "application_name": "walreceiver", "client_addr": "10.0.0.2", "state": "streaming"}, value: 0.5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pid": "4243", "application_name": "", "client_addr": "", "state": "startup"}, value: 120, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pid": "4245", "application_name": "walreceiver", "client_addr": "10.0.0.2", "state": "streaming"}, value: 2, metricType: dto.MetricType_GAUGE},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
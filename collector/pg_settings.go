@@ -0,0 +1,149 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const settingsSubsystem = "settings"
+
+func init() {
+	registerCollector(settingsSubsystem, defaultDisabled, NewPGSettingsCollector)
+}
+
+var settingsInclude = kingpin.Flag("collector.settings.include", "Comma-separated list of settings to report the current value of.").Default("").String()
+
+type PGSettingsCollector struct {
+	log      log.Logger
+	settings []string
+}
+
+func NewPGSettingsCollector(config collectorConfig) (Collector, error) {
+	// Setting names are case-insensitive. A setting listed twice would be
+	// exported as two identical series, which fails the whole scrape.
+	var settings []string
+	seen := make(map[string]bool)
+	for _, setting := range splitList(*settingsInclude) {
+		if key := strings.ToLower(setting); !seen[key] {
+			seen[key] = true
+			settings = append(settings, setting)
+		}
+	}
+	return &PGSettingsCollector{
+		log:      config.logger,
+		settings: settings,
+	}, nil
+}
+
+var (
+	settingsCurrentValue = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, settingsSubsystem, "current_value"),
+		"Current value of the setting as shown by SHOW, with memory converted to bytes and time to seconds",
+		[]string{"name"},
+		prometheus.Labels{},
+	)
+
+	// current_setting is the function form of SHOW, which cannot take a
+	// bind parameter. Unknown settings are returned as NULL.
+	settingsCurrentValueQuery = `SELECT current_setting($1, true)`
+)
+
+func (c *PGSettingsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	for _, name := range c.settings {
+		var value sql.NullString
+		if err := db.QueryRowContext(ctx, settingsCurrentValueQuery, name).Scan(&value); err != nil {
+			return err
+		}
+		if !value.Valid {
+			level.Debug(c.log).Log("msg", "Skipping unknown setting", "setting", name)
+			continue
+		}
+
+		v, err := parseSettingValue(value.String)
+		if err != nil {
+			level.Debug(c.log).Log("msg", "Skipping setting without a numeric value", "setting", name, "err", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			settingsCurrentValue,
+			prometheus.GaugeValue,
+			v,
+			name,
+		)
+	}
+	return nil
+}
+
+var (
+	settingValueRegex = regexp.MustCompile(`^(-?[0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)$`)
+
+	// settingUnits maps the units SHOW uses to the factor converting them
+	// to bytes or seconds.
+	settingUnits = map[string]float64{
+		"":    1,
+		"B":   1,
+		"kB":  1 << 10,
+		"MB":  1 << 20,
+		"GB":  1 << 30,
+		"TB":  1 << 40,
+		"us":  1e-6,
+		"ms":  1e-3,
+		"s":   1,
+		"min": 60,
+		"h":   60 * 60,
+		"d":   24 * 60 * 60,
+	}
+)
+
+// parseSettingValue converts a value as displayed by SHOW into a float,
+// converting memory units to bytes, time units to seconds and booleans to
+// 1 or 0.
+func parseSettingValue(value string) (float64, error) {
+	switch value {
+	case "on", "true":
+		return 1, nil
+	case "off", "false":
+		return 0, nil
+	}
+
+	m := settingValueRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, fmt.Errorf("cannot parse setting value %q", value)
+	}
+	factor, ok := settingUnits[m[2]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q in setting value %q", m[2], value)
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	// -1 conventionally disables a setting and is not scaled.
+	if v == -1 {
+		return v, nil
+	}
+	return v * factor, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGSettingsCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	for _, setting := range []struct {
+		name  string
+		value any
+	}{
+		{"shared_buffers", "128MB"},
+		{"wal_level", "replica"},
+		{"custom.missing", nil},
+		{"statement_timeout", "30s"},
+	} {
+		mock.ExpectQuery(sanitizeQuery(settingsCurrentValueQuery)).WithArgs(setting.name).
+			WillReturnRows(sqlmock.NewRows([]string{"current_setting"}).AddRow(setting.value))
+	}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGSettingsCollector{
+			log:      log.With(log.NewNopLogger(), "collector", "settings"),
+			settings: []string{"shared_buffers", "wal_level", "custom.missing", "statement_timeout"},
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGSettingsCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"name": "shared_buffers"}, metricType: dto.MetricType_GAUGE, value: 134217728},
+		{labels: labelMap{"name": "statement_timeout"}, metricType: dto.MetricType_GAUGE, value: 30},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestNewPGSettingsCollectorDeduplicates(t *testing.T) {
+	include := *settingsInclude
+	defer func() { *settingsInclude = include }()
+	*settingsInclude = "work_mem, shared_buffers,,Work_Mem,work_mem"
+
+	c, err := NewPGSettingsCollector(collectorConfig{logger: log.NewNopLogger()})
+	if err != nil {
+		t.Fatalf("Error creating PGSettingsCollector: %s", err)
+	}
+	if got, want := c.(*PGSettingsCollector).settings, []string{"work_mem", "shared_buffers"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("settings = %q, want %q", got, want)
+	}
+}
+
+func TestParseSettingValue(t *testing.T) {
+	for value, want := range map[string]float64{
+		"on":    1,
+		"off":   0,
+		"200":   200,
+		"1.1":   1.1,
+		"-1":    -1,
+		"8kB":   8192,
+		"1GB":   1 << 30,
+		"500ms": 0.5,
+		"5min":  300,
+		"1d":    86400,
+	} {
+		got, err := parseSettingValue(value)
+		if err != nil {
+			t.Errorf("parseSettingValue(%q) returned error: %s", value, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseSettingValue(%q) = %f, want %f", value, got, want)
+		}
+	}
+
+	for _, value := range []string{"replica", "10 parsecs", ""} {
+		if _, err := parseSettingValue(value); err == nil {
+			t.Errorf("parseSettingValue(%q) should have returned an error", value)
+		}
+	}
+}
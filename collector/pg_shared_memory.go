@@ -0,0 +1,128 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const sharedMemorySubsystem = "shared_memory"
+
+func init() {
+	registerCollector(sharedMemorySubsystem, defaultDisabled, NewPGSharedMemoryCollector)
+}
+
+type PGSharedMemoryCollector struct {
+	log log.Logger
+}
+
+func NewPGSharedMemoryCollector(config collectorConfig) (Collector, error) {
+	return &PGSharedMemoryCollector{log: config.logger}, nil
+}
+
+var (
+	sharedMemoryHugePages = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sharedMemorySubsystem, "huge_pages"),
+		"Configured huge_pages setting (try, on or off)",
+		[]string{"setting"},
+		prometheus.Labels{},
+	)
+	sharedMemoryHugePagesStatus = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sharedMemorySubsystem, "huge_pages_status"),
+		"Whether huge pages were actually allocated for the main shared memory area (on, off or unknown)",
+		[]string{"status"},
+		prometheus.Labels{},
+	)
+	sharedMemorySharedBuffersBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sharedMemorySubsystem, "shared_buffers_bytes"),
+		"Configured shared_buffers, in bytes",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	sharedMemoryQuery = `
+	SELECT
+		current_setting('huge_pages') AS huge_pages,
+		pg_size_bytes(current_setting('shared_buffers')) AS shared_buffers_bytes
+	`
+
+	// huge_pages_status was added in PostgreSQL 17. Before that, a
+	// huge_pages=try which fell back to regular pages is only visible in
+	// the server log.
+	sharedMemoryQuery17 = `
+	SELECT
+		current_setting('huge_pages') AS huge_pages,
+		pg_size_bytes(current_setting('shared_buffers')) AS shared_buffers_bytes,
+		current_setting('huge_pages_status') AS huge_pages_status
+	`
+)
+
+func (c *PGSharedMemoryCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_size_bytes was added in PostgreSQL 9.6
+	if instance.version.LT(semver.MustParse("9.6.0")) {
+		level.Debug(c.log).Log("msg", "pg_size_bytes is not available before PostgreSQL 9.6")
+		return nil
+	}
+
+	statusAvail := instance.version.GE(semver.MustParse("17.0.0"))
+	query := sharedMemoryQuery
+	if statusAvail {
+		query = sharedMemoryQuery17
+	}
+
+	db := instance.getDB()
+	var hugePages, hugePagesStatus sql.NullString
+	var sharedBuffersBytes sql.NullFloat64
+	r := []any{&hugePages, &sharedBuffersBytes}
+	if statusAvail {
+		r = append(r, &hugePagesStatus)
+	}
+	if err := db.QueryRowContext(ctx, query).Scan(r...); err != nil {
+		return err
+	}
+
+	if hugePages.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			sharedMemoryHugePages,
+			prometheus.GaugeValue,
+			1,
+			hugePages.String,
+		)
+	}
+	if hugePagesStatus.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			sharedMemoryHugePagesStatus,
+			prometheus.GaugeValue,
+			1,
+			hugePagesStatus.String,
+		)
+	}
+
+	sharedBuffersBytesMetric := 0.0
+	if sharedBuffersBytes.Valid {
+		sharedBuffersBytesMetric = sharedBuffersBytes.Float64
+	}
+	ch <- prometheus.MustNewConstMetric(
+		sharedMemorySharedBuffersBytes,
+		prometheus.GaugeValue,
+		sharedBuffersBytesMetric,
+	)
+	return nil
+}
@@ -0,0 +1,574 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statActivitySubsystem = "stat_activity"
+
+func init() {
+	registerCollector(statActivitySubsystem, defaultEnabled, NewPGStatActivityCollector)
+}
+
+var (
+	statActivityExcludeApplications        = kingpin.Flag("collector.stat_activity.exclude-applications", "Comma-separated list of application_name values to leave out of pg_stat_activity_by_application.").Default("").String()
+	statActivityIdleInTransactionThreshold = kingpin.Flag("collector.stat_activity.idle-in-transaction-threshold", "Also count the sessions idle in transaction for longer than this duration. Disabled when 0.").Default("0s").Duration()
+)
+
+type PGStatActivityCollector struct {
+	log                        log.Logger
+	databases                  databaseFilter
+	excludeApplications        []string
+	idleInTransactionThreshold time.Duration
+}
+
+func NewPGStatActivityCollector(config collectorConfig) (Collector, error) {
+	return &PGStatActivityCollector{
+		log:                        config.logger,
+		databases:                  newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		excludeApplications:        splitList(*statActivityExcludeApplications),
+		idleInTransactionThreshold: *statActivityIdleInTransactionThreshold,
+	}, nil
+}
+
+var (
+	// Every query leaves out the exporter's own connection by
+	// pg_backend_pid(), as it does not set an application_name.
+	statActivityIdleConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "idle_connections"),
+		"Number of backends in the idle state, not counting idle in transaction",
+		[]string{"datname", "usename"},
+		prometheus.Labels{},
+	)
+
+	statActivityIdleQuery = `
+	SELECT
+		datname,
+		usename,
+		COUNT(*) AS connections
+	FROM pg_catalog.pg_stat_activity
+	WHERE state = 'idle' AND pid <> pg_backend_pid()
+	GROUP BY datname, usename
+	`
+
+	statActivityByApplication = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "by_application"),
+		"Number of client backends by application_name and state",
+		[]string{"application_name", "state"},
+		prometheus.Labels{},
+	)
+
+	statActivityByApplicationQuery = `
+	SELECT
+		COALESCE(NULLIF(application_name, ''), 'unknown') AS application_name,
+		state,
+		COUNT(*) AS connections
+	FROM pg_catalog.pg_stat_activity
+	WHERE state IS NOT NULL AND pid <> pg_backend_pid()
+		AND backend_type = 'client backend'
+	GROUP BY COALESCE(NULLIF(application_name, ''), 'unknown'), state
+	`
+
+	// pg_stat_activity only lists client backends before PostgreSQL 10,
+	// which also has no backend_type.
+	statActivityByApplicationQueryPre10 = `
+	SELECT
+		COALESCE(NULLIF(application_name, ''), 'unknown') AS application_name,
+		state,
+		COUNT(*) AS connections
+	FROM pg_catalog.pg_stat_activity
+	WHERE state IS NOT NULL AND pid <> pg_backend_pid()
+	GROUP BY COALESCE(NULLIF(application_name, ''), 'unknown'), state
+	`
+
+	statActivityConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "connections"),
+		"Number of client connections by database, user, state and wait event type. Backends which are not waiting have wait_event_type=\"none\"",
+		[]string{"datname", "usename", "state", "wait_event_type"},
+		prometheus.Labels{},
+	)
+
+	statActivityConnectionsQuery = `
+	SELECT
+		datname,
+		usename,
+		state,
+		wait_event_type,
+		COUNT(*) AS connections
+	FROM pg_catalog.pg_stat_activity
+	WHERE state IS NOT NULL AND pid <> pg_backend_pid()
+	GROUP BY datname, usename, state, wait_event_type
+	`
+
+	statActivityMaxTxDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "max_tx_duration_seconds"),
+		"Age of the oldest open transaction in the database, in seconds",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
+
+	statActivityMaxTxDurationQuery = `
+	SELECT
+		datname,
+		COALESCE(MAX(EXTRACT(EPOCH FROM now() - xact_start)), 0) AS max_tx_duration
+	FROM pg_catalog.pg_stat_activity
+	WHERE datname IS NOT NULL AND pid <> pg_backend_pid()
+	GROUP BY datname
+	`
+
+	statActivityIdleInTransactionSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "idle_in_transaction_seconds"),
+		"Time the oldest session of this user has been idle in transaction in this database, in seconds",
+		[]string{"datname", "usename"},
+		prometheus.Labels{},
+	)
+	statActivityIdleInTransactionOverThreshold = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "idle_in_transaction_over_threshold"),
+		"Number of sessions idle in transaction for longer than --collector.stat_activity.idle-in-transaction-threshold",
+		[]string{"datname", "usename"},
+		prometheus.Labels{},
+	)
+
+	statActivityIdleInTransactionQuery = `
+	SELECT
+		datname,
+		usename,
+		MAX(EXTRACT(EPOCH FROM now() - state_change)) AS max_seconds,
+		SUM(CASE WHEN EXTRACT(EPOCH FROM now() - state_change) > $1 THEN 1 ELSE 0 END) AS over_threshold
+	FROM pg_catalog.pg_stat_activity
+	WHERE state IN ('idle in transaction', 'idle in transaction (aborted)') AND pid <> pg_backend_pid()
+	GROUP BY datname, usename
+	`
+
+	statActivityBackends = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "backends"),
+		"Number of backends by database and backend type. Together with the exporter's own connection these make up pg_stat_database_numbackends, which does not tell client connections from background workers",
+		[]string{"datname", "backend_type"},
+		prometheus.Labels{},
+	)
+
+	statActivityBackendsQuery = `
+	SELECT
+		datname,
+		backend_type,
+		COUNT(*) AS backends
+	FROM pg_catalog.pg_stat_activity
+	WHERE pid <> pg_backend_pid()
+	GROUP BY datname, backend_type
+	`
+
+	statActivityLWLockWaits = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "lwlock_waits"),
+		"Number of backends waiting on a lightweight lock, by wait event",
+		[]string{"wait_event"},
+		prometheus.Labels{},
+	)
+
+	statActivityLWLockQuery = `
+	SELECT
+		wait_event,
+		COUNT(*) AS waits
+	FROM pg_catalog.pg_stat_activity
+	WHERE wait_event_type = 'LWLock' AND pid <> pg_backend_pid()
+	GROUP BY wait_event
+	`
+
+	statActivityIOWaits = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statActivitySubsystem, "io_waits"),
+		"Number of backends waiting on an I/O operation, by wait event",
+		[]string{"wait_event"},
+		prometheus.Labels{},
+	)
+
+	statActivityIOWaitsQuery = `
+	SELECT
+		wait_event,
+		COUNT(*) AS waits
+	FROM pg_catalog.pg_stat_activity
+	WHERE wait_event_type = 'IO' AND pid <> pg_backend_pid()
+	GROUP BY wait_event
+	`
+)
+
+func (c *PGStatActivityCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	if err := c.updateIdleConnections(ctx, db, ch); err != nil {
+		return err
+	}
+	byApplicationQuery := statActivityByApplicationQuery
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		byApplicationQuery = statActivityByApplicationQueryPre10
+	}
+	if err := c.updateByApplication(ctx, db, ch, byApplicationQuery); err != nil {
+		return err
+	}
+	if err := c.updateMaxTxDuration(ctx, db, ch); err != nil {
+		return err
+	}
+	if err := c.updateIdleInTransaction(ctx, db, ch); err != nil {
+		return err
+	}
+
+	// wait_event_type was added in PostgreSQL 9.6
+	if instance.version.GE(semver.MustParse("9.6.0")) {
+		if err := c.updateConnections(ctx, db, ch); err != nil {
+			return err
+		}
+	}
+
+	// backend_type and the IO wait event type were added in PostgreSQL 10,
+	// which also merged LWLockNamed and LWLockTranche into LWLock
+	if instance.version.GE(semver.MustParse("10.0.0")) {
+		if err := c.updateWaitEvents(ctx, db, ch, statActivityLWLockQuery, statActivityLWLockWaits); err != nil {
+			return err
+		}
+		if err := c.updateWaitEvents(ctx, db, ch, statActivityIOWaitsQuery, statActivityIOWaits); err != nil {
+			return err
+		}
+		return c.updateBackendTypes(ctx, db, ch)
+	}
+	return nil
+}
+
+func (c *PGStatActivityCollector) updateIdleConnections(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		statActivityIdleQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, usename sql.NullString
+		var connections sql.NullInt64
+
+		if err := rows.Scan(&datname, &usename, &connections); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		usenameLabel := "unknown"
+		if usename.Valid {
+			usenameLabel = usename.String
+		}
+
+		connectionsMetric := 0.0
+		if connections.Valid {
+			connectionsMetric = float64(connections.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			statActivityIdleConnections,
+			prometheus.GaugeValue,
+			connectionsMetric,
+			datnameLabel, usenameLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *PGStatActivityCollector) updateByApplication(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, query string) error {
+	rows, err := db.QueryContext(ctx,
+		query)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// application_name is never NULL, the query already reports
+		// clients that do not set it as "unknown".
+		var applicationName string
+		var state sql.NullString
+		var connections sql.NullInt64
+
+		if err := rows.Scan(&applicationName, &state, &connections); err != nil {
+			return err
+		}
+
+		// Excluded applications are dropped from the grouped rows.
+		if sliceContains(c.excludeApplications, applicationName) {
+			continue
+		}
+
+		stateLabel := "unknown"
+		if state.Valid {
+			stateLabel = state.String
+		}
+
+		connectionsMetric := 0.0
+		if connections.Valid {
+			connectionsMetric = float64(connections.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			statActivityByApplication,
+			prometheus.GaugeValue,
+			connectionsMetric,
+			applicationName, stateLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *PGStatActivityCollector) updateMaxTxDuration(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		statActivityMaxTxDurationQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname sql.NullString
+		var maxTxDuration sql.NullFloat64
+
+		if err := rows.Scan(&datname, &maxTxDuration); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		if !datname.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			statActivityMaxTxDuration,
+			prometheus.GaugeValue,
+			maxTxDuration.Float64,
+			datname.String,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *PGStatActivityCollector) updateIdleInTransaction(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		statActivityIdleInTransactionQuery, c.idleInTransactionThreshold.Seconds())
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, usename sql.NullString
+		var maxSeconds sql.NullFloat64
+		var overThreshold sql.NullInt64
+
+		if err := rows.Scan(&datname, &usename, &maxSeconds, &overThreshold); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		usenameLabel := "unknown"
+		if usename.Valid {
+			usenameLabel = usename.String
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			statActivityIdleInTransactionSeconds,
+			prometheus.GaugeValue,
+			maxSeconds.Float64,
+			datnameLabel, usenameLabel,
+		)
+		if c.idleInTransactionThreshold > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				statActivityIdleInTransactionOverThreshold,
+				prometheus.GaugeValue,
+				float64(overThreshold.Int64),
+				datnameLabel, usenameLabel,
+			)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *PGStatActivityCollector) updateConnections(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		statActivityConnectionsQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, usename, state, waitEventType sql.NullString
+		var connections sql.NullInt64
+
+		if err := rows.Scan(&datname, &usename, &state, &waitEventType, &connections); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		usenameLabel := "unknown"
+		if usename.Valid {
+			usenameLabel = usename.String
+		}
+		stateLabel := "unknown"
+		if state.Valid {
+			stateLabel = state.String
+		}
+		waitEventTypeLabel := "none"
+		if waitEventType.Valid {
+			waitEventTypeLabel = waitEventType.String
+		}
+
+		connectionsMetric := 0.0
+		if connections.Valid {
+			connectionsMetric = float64(connections.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			statActivityConnections,
+			prometheus.GaugeValue,
+			connectionsMetric,
+			datnameLabel, usenameLabel, stateLabel, waitEventTypeLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// updateWaitEvents emits the number of backends per wait event returned by
+// query, which counts the backends waiting on one wait event type.
+func (c *PGStatActivityCollector) updateWaitEvents(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, query string, desc *prometheus.Desc) error {
+	rows, err := db.QueryContext(ctx,
+		query)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var waitEvent sql.NullString
+		var waits sql.NullInt64
+
+		if err := rows.Scan(&waitEvent, &waits); err != nil {
+			return err
+		}
+
+		waitEventLabel := "unknown"
+		if waitEvent.Valid {
+			waitEventLabel = waitEvent.String
+		}
+
+		waitsMetric := 0.0
+		if waits.Valid {
+			waitsMetric = float64(waits.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			desc,
+			prometheus.GaugeValue,
+			waitsMetric,
+			waitEventLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *PGStatActivityCollector) updateBackendTypes(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx,
+		statActivityBackendsQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, backendType sql.NullString
+		var backends sql.NullInt64
+
+		if err := rows.Scan(&datname, &backendType, &backends); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		// Background processes such as the checkpointer are not
+		// connected to a database.
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		backendTypeLabel := "unknown"
+		if backendType.Valid {
+			backendTypeLabel = backendType.String
+		}
+
+		backendsMetric := 0.0
+		if backends.Valid {
+			backendsMetric = float64(backends.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			statActivityBackends,
+			prometheus.GaugeValue,
+			backendsMetric,
+			datnameLabel, backendTypeLabel,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGStatActivityCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	rows := sqlmock.NewRows([]string{"datname", "usename", "connections"}).
+		AddRow("postgres", "app", 12).
+		AddRow(nil, nil, 3)
+	mock.ExpectQuery(sanitizeQuery(statActivityIdleQuery)).WillReturnRows(rows)
+
+	applicationRows := sqlmock.NewRows([]string{"application_name", "state", "connections"}).
+		AddRow("billing", "active", 4).
+		AddRow("unknown", "idle", 2).
+		AddRow("pgbouncer", "idle", 30).
+		AddRow("unknown", nil, 1)
+	mock.ExpectQuery(sanitizeQuery(statActivityByApplicationQueryPre10)).WillReturnRows(applicationRows)
+
+	txRows := sqlmock.NewRows([]string{"datname", "max_tx_duration"}).
+		AddRow("postgres", 42.5).
+		AddRow("template1", 0)
+	mock.ExpectQuery(sanitizeQuery(statActivityMaxTxDurationQuery)).WillReturnRows(txRows)
+
+	idleInTxRows := sqlmock.NewRows([]string{"datname", "usename", "max_seconds", "over_threshold"}).
+		AddRow("postgres", "app", 600.5, 2)
+	mock.ExpectQuery(sanitizeQuery(statActivityIdleInTransactionQuery)).WithArgs(float64(300)).WillReturnRows(idleInTxRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatActivityCollector{
+			excludeApplications:        []string{"pgbouncer"},
+			idleInTransactionThreshold: 5 * time.Minute,
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatActivityCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "postgres", "usename": "app"}, metricType: dto.MetricType_GAUGE, value: 12},
+		{labels: labelMap{"datname": "unknown", "usename": "unknown"}, metricType: dto.MetricType_GAUGE, value: 3},
+		{labels: labelMap{"application_name": "billing", "state": "active"}, metricType: dto.MetricType_GAUGE, value: 4},
+		{labels: labelMap{"application_name": "unknown", "state": "idle"}, metricType: dto.MetricType_GAUGE, value: 2},
+		{labels: labelMap{"application_name": "unknown", "state": "unknown"}, metricType: dto.MetricType_GAUGE, value: 1},
+		{labels: labelMap{"datname": "postgres"}, metricType: dto.MetricType_GAUGE, value: 42.5},
+		{labels: labelMap{"datname": "template1"}, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: labelMap{"datname": "postgres", "usename": "app"}, metricType: dto.MetricType_GAUGE, value: 600.5},
+		{labels: labelMap{"datname": "postgres", "usename": "app"}, metricType: dto.MetricType_GAUGE, value: 2},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatActivityCollectorLWLockWaits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	idleRows := sqlmock.NewRows([]string{"datname", "usename", "connections"}).
+		AddRow("postgres", "app", 12)
+	mock.ExpectQuery(sanitizeQuery(statActivityIdleQuery)).WillReturnRows(idleRows)
+	mock.ExpectQuery(sanitizeQuery(statActivityByApplicationQuery)).WillReturnRows(sqlmock.NewRows([]string{"application_name", "state", "connections"}))
+	mock.ExpectQuery(sanitizeQuery(statActivityMaxTxDurationQuery)).WillReturnRows(sqlmock.NewRows([]string{"datname", "max_tx_duration"}))
+
+	// Without a threshold only the age of the oldest session is reported.
+	idleInTxRows := sqlmock.NewRows([]string{"datname", "usename", "max_seconds", "over_threshold"}).
+		AddRow("postgres", "app", 30, 0)
+	mock.ExpectQuery(sanitizeQuery(statActivityIdleInTransactionQuery)).WithArgs(float64(0)).WillReturnRows(idleInTxRows)
+
+	connectionRows := sqlmock.NewRows([]string{"datname", "usename", "state", "wait_event_type", "connections"}).
+		AddRow("postgres", "app", "active", nil, 3).
+		AddRow("postgres", "app", "idle in transaction", "Client", 2)
+	mock.ExpectQuery(sanitizeQuery(statActivityConnectionsQuery)).WillReturnRows(connectionRows)
+
+	lwlockRows := sqlmock.NewRows([]string{"wait_event", "waits"}).
+		AddRow("WALWrite", 4).
+		AddRow("BufferContent", 2)
+	mock.ExpectQuery(sanitizeQuery(statActivityLWLockQuery)).WillReturnRows(lwlockRows)
+
+	ioRows := sqlmock.NewRows([]string{"wait_event", "waits"}).
+		AddRow("DataFileRead", 7).
+		AddRow("WALSync", 1)
+	mock.ExpectQuery(sanitizeQuery(statActivityIOWaitsQuery)).WillReturnRows(ioRows)
+
+	backendRows := sqlmock.NewRows([]string{"datname", "backend_type", "backends"}).
+		AddRow("postgres", "client backend", 14).
+		AddRow("postgres", "parallel worker", 2).
+		AddRow(nil, "checkpointer", 1)
+	mock.ExpectQuery(sanitizeQuery(statActivityBackendsQuery)).WillReturnRows(backendRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatActivityCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatActivityCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "postgres", "usename": "app"}, metricType: dto.MetricType_GAUGE, value: 12},
+		{labels: labelMap{"datname": "postgres", "usename": "app"}, metricType: dto.MetricType_GAUGE, value: 30},
+		{labels: labelMap{"datname": "postgres", "usename": "app", "state": "active", "wait_event_type": "none"}, metricType: dto.MetricType_GAUGE, value: 3},
+		{labels: labelMap{"datname": "postgres", "usename": "app", "state": "idle in transaction", "wait_event_type": "Client"}, metricType: dto.MetricType_GAUGE, value: 2},
+		{labels: labelMap{"wait_event": "WALWrite"}, metricType: dto.MetricType_GAUGE, value: 4},
+		{labels: labelMap{"wait_event": "BufferContent"}, metricType: dto.MetricType_GAUGE, value: 2},
+		{labels: labelMap{"wait_event": "DataFileRead"}, metricType: dto.MetricType_GAUGE, value: 7},
+		{labels: labelMap{"wait_event": "WALSync"}, metricType: dto.MetricType_GAUGE, value: 1},
+		{labels: labelMap{"datname": "postgres", "backend_type": "client backend"}, metricType: dto.MetricType_GAUGE, value: 14},
+		{labels: labelMap{"datname": "postgres", "backend_type": "parallel worker"}, metricType: dto.MetricType_GAUGE, value: 2},
+		{labels: labelMap{"datname": "unknown", "backend_type": "checkpointer"}, metricType: dto.MetricType_GAUGE, value: 1},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatActivityCollectorPG96(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("9.6.0")}
+
+	mock.ExpectQuery(sanitizeQuery(statActivityIdleQuery)).WillReturnRows(sqlmock.NewRows([]string{"datname", "usename", "connections"}))
+	mock.ExpectQuery(sanitizeQuery(statActivityByApplicationQueryPre10)).WillReturnRows(sqlmock.NewRows([]string{"application_name", "state", "connections"}))
+	mock.ExpectQuery(sanitizeQuery(statActivityMaxTxDurationQuery)).WillReturnRows(sqlmock.NewRows([]string{"datname", "max_tx_duration"}))
+	mock.ExpectQuery(sanitizeQuery(statActivityIdleInTransactionQuery)).WithArgs(float64(0)).WillReturnRows(sqlmock.NewRows([]string{"datname", "usename", "max_seconds", "over_threshold"}))
+
+	// PostgreSQL 9.6 reports lightweight locks as LWLockNamed or
+	// LWLockTranche, so the LWLock query is not run.
+	connectionRows := sqlmock.NewRows([]string{"datname", "usename", "state", "wait_event_type", "connections"}).
+		AddRow("postgres", "app", "active", "LWLockNamed", 3)
+	mock.ExpectQuery(sanitizeQuery(statActivityConnectionsQuery)).WillReturnRows(connectionRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatActivityCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatActivityCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "postgres", "usename": "app", "state": "active", "wait_event_type": "LWLockNamed"}, metricType: dto.MetricType_GAUGE, value: 3},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statArchiverSubsystem = "stat_archiver"
+
+func init() {
+	registerCollector(statArchiverSubsystem, defaultEnabled, NewPGStatArchiverCollector)
+}
+
+// PGStatArchiverCollector exposes WAL archiving health from pg_stat_archiver.
+// The built-in pg_stat_archiver metric map also exports archived_count,
+// failed_count and last_archive_age, so the metrics here use names that do not
+// collide with those.
+type PGStatArchiverCollector struct {
+	log log.Logger
+}
+
+func NewPGStatArchiverCollector(config collectorConfig) (Collector, error) {
+	return &PGStatArchiverCollector{log: config.logger}, nil
+}
+
+var (
+	statArchiverArchived = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statArchiverSubsystem, "archived_total"),
+		"Number of WAL files that have been successfully archived",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statArchiverFailed = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statArchiverSubsystem, "failed_total"),
+		"Number of failed attempts for archiving WAL files",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statArchiverLastArchivedTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statArchiverSubsystem, "last_archived_time_seconds"),
+		"Time of the last successful archive operation as a Unix epoch",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statArchiverLastFailedTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statArchiverSubsystem, "last_failed_time_seconds"),
+		"Time of the last failed archival operation as a Unix epoch",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statArchiverSecondsSinceLastArchive = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statArchiverSubsystem, "seconds_since_last_archive"),
+		"Seconds since the last successful archive operation",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statArchiverFailureRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statArchiverSubsystem, "failure_ratio"),
+		"Failed archival attempts divided by all archival attempts since the statistics were last reset",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statArchiverLastFailedWALInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statArchiverSubsystem, "last_failed_wal_info"),
+		"Name of the WAL file of the last failed archival operation",
+		[]string{"last_failed_wal"},
+		prometheus.Labels{},
+	)
+
+	statArchiverQuery = `
+	SELECT
+		archived_count,
+		failed_count,
+		last_failed_wal,
+		EXTRACT(EPOCH FROM last_archived_time) AS last_archived_time,
+		EXTRACT(EPOCH FROM last_failed_time) AS last_failed_time,
+		EXTRACT(EPOCH FROM now() - last_archived_time) AS seconds_since_last_archive
+	FROM pg_stat_archiver
+	`
+)
+
+func (c *PGStatArchiverCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+
+	var archivedCount, failedCount sql.NullInt64
+	var lastFailedWAL sql.NullString
+	var lastArchivedTime, lastFailedTime, sinceLastArchive sql.NullFloat64
+	err := db.QueryRowContext(ctx,
+		statArchiverQuery,
+	).Scan(&archivedCount, &failedCount, &lastFailedWAL, &lastArchivedTime, &lastFailedTime, &sinceLastArchive)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		statArchiverArchived,
+		prometheus.CounterValue,
+		float64(archivedCount.Int64),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		statArchiverFailed,
+		prometheus.CounterValue,
+		float64(failedCount.Int64),
+	)
+
+	// The timestamps are NULL until the first archival attempt of each kind.
+	if lastArchivedTime.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			statArchiverLastArchivedTime,
+			prometheus.GaugeValue,
+			lastArchivedTime.Float64,
+		)
+	}
+	if lastFailedTime.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			statArchiverLastFailedTime,
+			prometheus.GaugeValue,
+			lastFailedTime.Float64,
+		)
+	}
+	if sinceLastArchive.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			statArchiverSecondsSinceLastArchive,
+			prometheus.GaugeValue,
+			sinceLastArchive.Float64,
+		)
+	}
+
+	attempts := archivedCount.Int64 + failedCount.Int64
+	if attempts > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			statArchiverFailureRatio,
+			prometheus.GaugeValue,
+			float64(failedCount.Int64)/float64(attempts),
+		)
+	}
+
+	if lastFailedWAL.Valid && lastFailedWAL.String != "" {
+		ch <- prometheus.MustNewConstMetric(
+			statArchiverLastFailedWALInfo,
+			prometheus.GaugeValue,
+			1,
+			lastFailedWAL.String,
+		)
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -27,10 +28,13 @@ func init() {
 }
 
 type PGStatBGWriterCollector struct {
+	deprecatedStatsReset bool
 }
 
 func NewPGStatBGWriterCollector(collectorConfig) (Collector, error) {
-	return &PGStatBGWriterCollector{}, nil
+	return &PGStatBGWriterCollector{
+		deprecatedStatsReset: *deprecatedStatsResetMetrics,
+	}, nil
 }
 
 var (
@@ -94,9 +98,34 @@ var (
 		[]string{},
 		prometheus.Labels{},
 	)
+	statBGWriterStatsResetTimestampDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, bgWriterSubsystem, "stats_reset_timestamp_seconds"),
+		"Time at which these statistics were last reset, in seconds since the Unix epoch",
+		[]string{},
+		prometheus.Labels{},
+	)
+	// Deprecated: use statBGWriterStatsResetTimestampDesc.
 	statBGWriterStatsResetDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, bgWriterSubsystem, "stats_reset_total"),
-		"Time at which these statistics were last reset",
+		"Time at which these statistics were last reset (DEPRECATED, use pg_stat_bgwriter_stats_reset_timestamp_seconds)",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statBGWriterSecondsSinceStatsResetDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, bgWriterSubsystem, "seconds_since_stats_reset"),
+		"Seconds since these statistics were last reset",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statBGWriterCheckpointCompletionTargetDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, bgWriterSubsystem, "checkpoint_completion_target"),
+		"Value of the checkpoint_completion_target setting, the fraction of the checkpoint interval over which checkpoint writes are spread",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statBGWriterCheckpointSyncRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, bgWriterSubsystem, "checkpoint_sync_ratio"),
+		"Checkpoint sync time divided by checkpoint write and sync time since the statistics were last reset. High values point at fsync bottlenecks",
 		[]string{},
 		prometheus.Labels{},
 	)
@@ -113,19 +142,53 @@ var (
 		,buffers_backend_fsync
 		,buffers_alloc
 		,stats_reset
+		,EXTRACT(EPOCH FROM now() - stats_reset) AS seconds_since_stats_reset
+		,current_setting('checkpoint_completion_target')::float AS checkpoint_completion_target
 	FROM pg_stat_bgwriter;`
+
+	// PostgreSQL 17 moved the checkpoint columns to pg_stat_checkpointer and
+	// dropped buffers_backend and buffers_backend_fsync in favour of
+	// pg_stat_io. The result keeps the column layout of statBGWriterQuery so
+	// the emitted metric names stay the same across versions.
+	statBGWriterQuery17 = `SELECT
+		c.num_timed AS checkpoints_timed
+		,c.num_requested AS checkpoints_req
+		,c.write_time AS checkpoint_write_time
+		,c.sync_time AS checkpoint_sync_time
+		,c.buffers_written AS buffers_checkpoint
+		,b.buffers_clean
+		,b.maxwritten_clean
+		,io.writes AS buffers_backend
+		,io.fsyncs AS buffers_backend_fsync
+		,b.buffers_alloc
+		,b.stats_reset
+		,EXTRACT(EPOCH FROM now() - b.stats_reset) AS seconds_since_stats_reset
+		,current_setting('checkpoint_completion_target')::float AS checkpoint_completion_target
+	FROM pg_stat_bgwriter b
+	CROSS JOIN pg_stat_checkpointer c
+	CROSS JOIN (
+		SELECT sum(writes) AS writes, sum(fsyncs) AS fsyncs
+		FROM pg_stat_io
+		WHERE object = 'relation'
+		AND backend_type NOT IN ('background writer', 'checkpointer')
+	) io;`
 )
 
-func (PGStatBGWriterCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+func (c PGStatBGWriterCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	query := statBGWriterQuery
+	if instance.version.GE(semver.MustParse("17.0.0")) {
+		query = statBGWriterQuery17
+	}
+
 	db := instance.getDB()
 	row := db.QueryRowContext(ctx,
-		statBGWriterQuery)
+		query)
 
 	var cpt, cpr, bcp, bc, mwc, bb, bbf, ba sql.NullInt64
-	var cpwt, cpst sql.NullFloat64
+	var cpwt, cpst, ssr, cct sql.NullFloat64
 	var sr sql.NullTime
 
-	err := row.Scan(&cpt, &cpr, &cpwt, &cpst, &bcp, &bc, &mwc, &bb, &bbf, &ba, &sr)
+	err := row.Scan(&cpt, &cpr, &cpwt, &cpst, &bcp, &bc, &mwc, &bb, &bbf, &ba, &sr, &ssr, &cct)
 	if err != nil {
 		return err
 	}
@@ -222,13 +285,41 @@ func (PGStatBGWriterCollector) Update(ctx context.Context, instance *instance, c
 	)
 	srMetric := 0.0
 	if sr.Valid {
-		srMetric = float64(sr.Time.Unix())
+		srMetric = timeToEpochSeconds(sr.Time)
 	}
 	ch <- prometheus.MustNewConstMetric(
-		statBGWriterStatsResetDesc,
-		prometheus.CounterValue,
+		statBGWriterStatsResetTimestampDesc,
+		prometheus.GaugeValue,
 		srMetric,
 	)
+	if c.deprecatedStatsReset {
+		ch <- prometheus.MustNewConstMetric(
+			statBGWriterStatsResetDesc,
+			prometheus.CounterValue,
+			srMetric,
+		)
+	}
+	if ssr.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			statBGWriterSecondsSinceStatsResetDesc,
+			prometheus.GaugeValue,
+			ssr.Float64,
+		)
+	}
+	if cct.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			statBGWriterCheckpointCompletionTargetDesc,
+			prometheus.GaugeValue,
+			cct.Float64,
+		)
+	}
+	if cpwtMetric+cpstMetric > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			statBGWriterCheckpointSyncRatioDesc,
+			prometheus.GaugeValue,
+			cpstMetric/(cpwtMetric+cpstMetric),
+		)
+	}
 
 	return nil
 }
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
@@ -43,7 +44,9 @@ func TestPGStatBGWriterCollector(t *testing.T) {
 		"buffers_backend",
 		"buffers_backend_fsync",
 		"buffers_alloc",
-		"stats_reset"}
+		"stats_reset",
+		"seconds_since_stats_reset",
+		"checkpoint_completion_target"}
 
 	srT, err := time.Parse("2006-01-02 15:04:05.00000-07", "2023-05-25 17:10:42.81132-07")
 	if err != nil {
@@ -51,7 +54,7 @@ func TestPGStatBGWriterCollector(t *testing.T) {
 	}
 
 	rows := sqlmock.NewRows(columns).
-		AddRow(354, 4945, 289097744, 1242257, int64(3275602074), 89320867, 450139, 2034563757, 0, int64(2725688749), srT)
+		AddRow(354, 4945, 289097744, 1242257, int64(3275602074), 89320867, 450139, 2034563757, 0, int64(2725688749), srT, 3600.5, 0.9)
 	mock.ExpectQuery(sanitizeQuery(statBGWriterQuery)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
@@ -75,7 +78,10 @@ func TestPGStatBGWriterCollector(t *testing.T) {
 		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 2034563757},
 		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 0},
 		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 2725688749},
-		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 1685059842},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1685059842.81132},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 3600.5},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 0.9},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1242257.0 / (289097744.0 + 1242257.0)},
 	}
 
 	convey.Convey("Metrics comparison", t, func() {
@@ -109,10 +115,12 @@ func TestPGStatBGWriterCollectorNullValues(t *testing.T) {
 		"buffers_backend",
 		"buffers_backend_fsync",
 		"buffers_alloc",
-		"stats_reset"}
+		"stats_reset",
+		"seconds_since_stats_reset",
+		"checkpoint_completion_target"}
 
 	rows := sqlmock.NewRows(columns).
-		AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	mock.ExpectQuery(sanitizeQuery(statBGWriterQuery)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
@@ -136,7 +144,78 @@ func TestPGStatBGWriterCollectorNullValues(t *testing.T) {
 		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 0},
 		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 0},
 		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 0},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatBGWriterCollectorPG17(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("17.0.0")}
+
+	columns := []string{
+		"checkpoints_timed",
+		"checkpoints_req",
+		"checkpoint_write_time",
+		"checkpoint_sync_time",
+		"buffers_checkpoint",
+		"buffers_clean",
+		"maxwritten_clean",
+		"buffers_backend",
+		"buffers_backend_fsync",
+		"buffers_alloc",
+		"stats_reset",
+		"seconds_since_stats_reset",
+		"checkpoint_completion_target"}
+
+	srT, err := time.Parse("2006-01-02 15:04:05.00000-07", "2023-05-25 17:10:42.81132-07")
+	if err != nil {
+		t.Fatalf("Error parsing time: %s", err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(354, 4945, 289097744, 1242257, int64(3275602074), 89320867, 450139, 2034563757, 0, int64(2725688749), srT, 3600.5, 0.9)
+	mock.ExpectQuery(sanitizeQuery(statBGWriterQuery17)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatBGWriterCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatBGWriterCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 354},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 4945},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 289097744},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 1242257},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 3275602074},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 89320867},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 450139},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 2034563757},
 		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 2725688749},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1685059842.81132},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 3600.5},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 0.9},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1242257.0 / (289097744.0 + 1242257.0)},
 	}
 
 	convey.Convey("Metrics comparison", t, func() {
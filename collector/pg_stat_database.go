@@ -32,11 +32,17 @@ func init() {
 }
 
 type PGStatDatabaseCollector struct {
-	log log.Logger
+	log                  log.Logger
+	databases            databaseFilter
+	deprecatedStatsReset bool
 }
 
 func NewPGStatDatabaseCollector(config collectorConfig) (Collector, error) {
-	return &PGStatDatabaseCollector{log: config.logger}, nil
+	return &PGStatDatabaseCollector{
+		log:                  config.logger,
+		databases:            newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		deprecatedStatsReset: *deprecatedStatsResetMetrics,
+	}, nil
 }
 
 var (
@@ -200,12 +206,28 @@ var (
 		[]string{"datid", "datname"},
 		prometheus.Labels{},
 	)
+	cacheHitRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cache", "hit_ratio"),
+		"Buffer cache hits divided by blocks accessed, summed over all reported databases",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statDatabaseStatsResetTimestamp = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"stats_reset_timestamp_seconds",
+	),
+		"Time at which these statistics were last reset, in seconds since the Unix epoch",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	// Deprecated: use statDatabaseStatsResetTimestamp.
 	statDatabaseStatsReset = prometheus.NewDesc(prometheus.BuildFQName(
 		namespace,
 		statDatabaseSubsystem,
 		"stats_reset",
 	),
-		"Time at which these statistics were last reset",
+		"Time at which these statistics were last reset (DEPRECATED, use pg_stat_database_stats_reset_timestamp_seconds)",
 		[]string{"datid", "datname"},
 		prometheus.Labels{},
 	)
@@ -218,10 +240,92 @@ var (
 		[]string{"datid", "datname"},
 		prometheus.Labels{},
 	)
+	statDatabaseIOTimeRatio = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"io_time_ratio",
+	),
+		"Time spent reading data file blocks divided by time spent executing SQL statements in this database. Requires track_io_timing",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseActiveTimeRatio = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"active_time_ratio",
+	),
+		"Time spent executing SQL statements divided by the total time sessions spent connected to this database",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseActiveTimePerCommit = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"active_time_per_commit_seconds",
+	),
+		"Time spent executing SQL statements divided by the number of committed transactions in this database, in seconds. "+
+			"This is a rough approximation of the time per transaction: active time also includes rolled back transactions and statements run outside explicit transactions",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseSessionTime = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"session_time_seconds_total",
+	),
+		"Time spent by database sessions in this database, in seconds",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseIdleInTransactionTime = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"idle_in_transaction_time_seconds_total",
+	),
+		"Time spent idling while in a transaction in this database, in seconds",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseSessions = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"sessions_total",
+	),
+		"Total number of sessions established to this database",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseSessionsAbandoned = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"sessions_abandoned_total",
+	),
+		"Number of database sessions to this database that were terminated because connection to the client was lost",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseSessionsFatal = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"sessions_fatal_total",
+	),
+		"Number of database sessions to this database that were terminated by fatal errors",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
+	statDatabaseSessionsKilled = prometheus.NewDesc(prometheus.BuildFQName(
+		namespace,
+		statDatabaseSubsystem,
+		"sessions_killed_total",
+	),
+		"Number of database sessions to this database that were terminated by operator intervention",
+		[]string{"datid", "datname"},
+		prometheus.Labels{},
+	)
 )
 
-func statDatabaseQuery(columns []string) string {
-	return fmt.Sprintf("SELECT %s FROM pg_stat_database;", strings.Join(columns, ","))
+func statDatabaseQuery(columns []string, where string) string {
+	return fmt.Sprintf("SELECT %s FROM pg_stat_database%s;", strings.Join(columns, ","), where)
 }
 
 func (c *PGStatDatabaseCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
@@ -251,20 +355,31 @@ func (c *PGStatDatabaseCollector) Update(ctx context.Context, instance *instance
 
 	activeTimeAvail := instance.version.GTE(semver.MustParse("14.0.0"))
 	if activeTimeAvail {
-		columns = append(columns, "active_time")
+		columns = append(columns,
+			"active_time",
+			"session_time",
+			"idle_in_transaction_time",
+			"sessions",
+			"sessions_abandoned",
+			"sessions_fatal",
+			"sessions_killed",
+		)
 	}
 
+	where, args := c.databases.where()
 	rows, err := db.QueryContext(ctx,
-		statDatabaseQuery(columns),
+		statDatabaseQuery(columns, where),
+		args...,
 	)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 
+	var totalBlksHit, totalBlksRead float64
 	for rows.Next() {
 		var datid, datname sql.NullString
-		var numBackends, xactCommit, xactRollback, blksRead, blksHit, tupReturned, tupFetched, tupInserted, tupUpdated, tupDeleted, conflicts, tempFiles, tempBytes, deadlocks, blkReadTime, blkWriteTime, activeTime sql.NullFloat64
+		var numBackends, xactCommit, xactRollback, blksRead, blksHit, tupReturned, tupFetched, tupInserted, tupUpdated, tupDeleted, conflicts, tempFiles, tempBytes, deadlocks, blkReadTime, blkWriteTime, activeTime, sessionTime, idleInTransactionTime, sessions, sessionsAbandoned, sessionsFatal, sessionsKilled sql.NullFloat64
 		var statsReset sql.NullTime
 
 		r := []any{
@@ -290,7 +405,7 @@ func (c *PGStatDatabaseCollector) Update(ctx context.Context, instance *instance
 		}
 
 		if activeTimeAvail {
-			r = append(r, &activeTime)
+			r = append(r, &activeTime, &sessionTime, &idleInTransactionTime, &sessions, &sessionsAbandoned, &sessionsFatal, &sessionsKilled)
 		}
 
 		err := rows.Scan(r...)
@@ -380,10 +495,12 @@ func (c *PGStatDatabaseCollector) Update(ctx context.Context, instance *instance
 			level.Debug(c.log).Log("msg", "No metric for stats_reset, will collect 0 instead")
 		}
 		if statsReset.Valid {
-			statsResetMetric = float64(statsReset.Time.Unix())
+			statsResetMetric = timeToEpochSeconds(statsReset.Time)
 		}
 
 		labels := []string{datid.String, datname.String}
+		totalBlksHit += blksHit.Float64
+		totalBlksRead += blksRead.Float64
 
 		ch <- prometheus.MustNewConstMetric(
 			statDatabaseNumbackends,
@@ -498,11 +615,19 @@ func (c *PGStatDatabaseCollector) Update(ctx context.Context, instance *instance
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			statDatabaseStatsReset,
-			prometheus.CounterValue,
+			statDatabaseStatsResetTimestamp,
+			prometheus.GaugeValue,
 			statsResetMetric,
 			labels...,
 		)
+		if c.deprecatedStatsReset {
+			ch <- prometheus.MustNewConstMetric(
+				statDatabaseStatsReset,
+				prometheus.CounterValue,
+				statsResetMetric,
+				labels...,
+			)
+		}
 
 		if activeTimeAvail {
 			ch <- prometheus.MustNewConstMetric(
@@ -511,7 +636,70 @@ func (c *PGStatDatabaseCollector) Update(ctx context.Context, instance *instance
 				activeTime.Float64/1000.0,
 				labels...,
 			)
+
+			if activeTime.Float64 > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					statDatabaseIOTimeRatio,
+					prometheus.GaugeValue,
+					blkReadTime.Float64/activeTime.Float64,
+					labels...,
+				)
+			}
+
+			if sessionTime.Valid && sessionTime.Float64 > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					statDatabaseActiveTimeRatio,
+					prometheus.GaugeValue,
+					activeTime.Float64/sessionTime.Float64,
+					labels...,
+				)
+			}
+
+			if xactCommit.Float64 > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					statDatabaseActiveTimePerCommit,
+					prometheus.GaugeValue,
+					activeTime.Float64/(xactCommit.Float64*1000.0),
+					labels...,
+				)
+			}
+
+			// Unlike the columns above, a NULL session column only skips
+			// its own metric rather than the whole row.
+			for _, session := range []struct {
+				desc  *prometheus.Desc
+				value sql.NullFloat64
+				scale float64
+			}{
+				{statDatabaseSessionTime, sessionTime, 1000.0},
+				{statDatabaseIdleInTransactionTime, idleInTransactionTime, 1000.0},
+				{statDatabaseSessions, sessions, 1},
+				{statDatabaseSessionsAbandoned, sessionsAbandoned, 1},
+				{statDatabaseSessionsFatal, sessionsFatal, 1},
+				{statDatabaseSessionsKilled, sessionsKilled, 1},
+			} {
+				if !session.value.Valid {
+					continue
+				}
+				ch <- prometheus.MustNewConstMetric(
+					session.desc,
+					prometheus.CounterValue,
+					session.value.Float64/session.scale,
+					labels...,
+				)
+			}
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if totalBlksHit+totalBlksRead > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			cacheHitRatio,
+			prometheus.GaugeValue,
+			totalBlksHit/(totalBlksHit+totalBlksRead),
+		)
+	}
 	return nil
 }
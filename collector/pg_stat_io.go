@@ -0,0 +1,320 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statIOSubsystem = "stat_io"
+
+func init() {
+	registerCollector(statIOSubsystem, defaultEnabled, NewPGStatIOCollector)
+}
+
+type PGStatIOCollector struct {
+	log log.Logger
+}
+
+func NewPGStatIOCollector(config collectorConfig) (Collector, error) {
+	return &PGStatIOCollector{log: config.logger}, nil
+}
+
+var (
+	statIOVacuumReads = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "vacuum_reads_total"),
+		"Number of read operations done by vacuum and analyze",
+		[]string{"backend_type", "object"},
+		prometheus.Labels{},
+	)
+	statIOVacuumWrites = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "vacuum_writes_total"),
+		"Number of write operations done by vacuum and analyze",
+		[]string{"backend_type", "object"},
+		prometheus.Labels{},
+	)
+	statIOVacuumReadBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "vacuum_read_bytes_total"),
+		"Number of bytes read by vacuum and analyze",
+		[]string{"backend_type", "object"},
+		prometheus.Labels{},
+	)
+	statIOVacuumWriteBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "vacuum_write_bytes_total"),
+		"Number of bytes written by vacuum and analyze",
+		[]string{"backend_type", "object"},
+		prometheus.Labels{},
+	)
+
+	statIOReads = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "reads_total"),
+		"Number of read operations",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOReadTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "read_time_seconds_total"),
+		"Time spent in read operations, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOReadLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "read_latency_seconds"),
+		"Average time per read operation, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOWrites = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "writes_total"),
+		"Number of write operations",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOWriteTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "write_time_seconds_total"),
+		"Time spent in write operations, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOWriteLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "write_latency_seconds"),
+		"Average time per write operation, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOWritebacks = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "writebacks_total"),
+		"Number of requests to the kernel to write data to permanent storage",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOWritebackTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "writeback_time_seconds_total"),
+		"Time spent in writeback operations, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOWritebackLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "writeback_latency_seconds"),
+		"Average time per writeback operation, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOHits = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "hits_total"),
+		"Number of times a desired block was found in a shared buffer",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOEvictions = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "evictions_total"),
+		"Number of times a block has been written out from a shared or local buffer to make it available for another use",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOReuses = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "reuses_total"),
+		"Number of times an existing buffer in a size-limited ring buffer was reused by a bulk read, bulk write or vacuum",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOExtends = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "extends_total"),
+		"Number of relation extend operations",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOExtendTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "extend_time_seconds_total"),
+		"Time spent in relation extend operations, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOExtendLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "extend_latency_seconds"),
+		"Average time per relation extend operation, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOFsyncs = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "fsyncs_total"),
+		"Number of fsync calls. These are only tracked in the normal context",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOFsyncTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "fsync_time_seconds_total"),
+		"Time spent in fsync operations, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+	statIOFsyncLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statIOSubsystem, "fsync_latency_seconds"),
+		"Average time per fsync operation, in seconds. Requires track_io_timing",
+		[]string{"backend_type", "object", "context"},
+		prometheus.Labels{},
+	)
+
+	statIOQuery = `
+	SELECT
+		backend_type,
+		object,
+		context,
+		reads,
+		read_time,
+		writes,
+		write_time,
+		writebacks,
+		writeback_time,
+		reads * op_bytes AS read_bytes,
+		writes * op_bytes AS write_bytes,
+		extends,
+		extend_time,
+		hits,
+		evictions,
+		reuses,
+		fsyncs,
+		fsync_time
+	FROM pg_catalog.pg_stat_io
+	`
+
+	// op_bytes was replaced by read_bytes and write_bytes in PostgreSQL 18
+	statIOQuery18 = `
+	SELECT
+		backend_type,
+		object,
+		context,
+		reads,
+		read_time,
+		writes,
+		write_time,
+		writebacks,
+		writeback_time,
+		read_bytes,
+		write_bytes,
+		extends,
+		extend_time,
+		hits,
+		evictions,
+		reuses,
+		fsyncs,
+		fsync_time
+	FROM pg_catalog.pg_stat_io
+	`
+)
+
+func (c *PGStatIOCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_stat_io was added in PostgreSQL 16
+	if instance.version.LT(semver.MustParse("16.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_stat_io is not available before PostgreSQL 16")
+		return nil
+	}
+
+	query := statIOQuery
+	if instance.version.GE(semver.MustParse("18.0.0")) {
+		query = statIOQuery18
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var backendType, object, ioContext sql.NullString
+		var reads, readTime, writes, writeTime, writebacks, writebackTime, readBytes, writeBytes sql.NullFloat64
+		var extends, extendTime, hits, evictions, reuses, fsyncs, fsyncTime sql.NullFloat64
+
+		if err := rows.Scan(&backendType, &object, &ioContext, &reads, &readTime, &writes, &writeTime, &writebacks, &writebackTime, &readBytes, &writeBytes, &extends, &extendTime, &hits, &evictions, &reuses, &fsyncs, &fsyncTime); err != nil {
+			return err
+		}
+
+		if !backendType.Valid || !object.Valid || !ioContext.Valid {
+			continue
+		}
+		labels := []string{backendType.String, object.String}
+		contextLabels := []string{backendType.String, object.String, ioContext.String}
+
+		c.emitTimed(ch, statIOReads, statIOReadTime, statIOReadLatency, reads, readTime, contextLabels)
+		c.emitTimed(ch, statIOWrites, statIOWriteTime, statIOWriteLatency, writes, writeTime, contextLabels)
+		c.emitTimed(ch, statIOWritebacks, statIOWritebackTime, statIOWritebackLatency, writebacks, writebackTime, contextLabels)
+		c.emitTimed(ch, statIOExtends, statIOExtendTime, statIOExtendLatency, extends, extendTime, contextLabels)
+		c.emitIfValid(ch, statIOHits, hits, contextLabels)
+		c.emitIfValid(ch, statIOEvictions, evictions, contextLabels)
+		c.emitIfValid(ch, statIOReuses, reuses, contextLabels)
+		c.emitTimed(ch, statIOFsyncs, statIOFsyncTime, statIOFsyncLatency, fsyncs, fsyncTime, contextLabels)
+
+		// Vacuum I/O is reported separately so that its share of the
+		// total I/O can be told apart from query I/O.
+		if ioContext.String == "vacuum" {
+			c.emitIfValid(ch, statIOVacuumReads, reads, labels)
+			c.emitIfValid(ch, statIOVacuumWrites, writes, labels)
+			c.emitIfValid(ch, statIOVacuumReadBytes, readBytes, labels)
+			c.emitIfValid(ch, statIOVacuumWriteBytes, writeBytes, labels)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// emitIfValid emits a counter for value, skipping the combinations of
+// backend type, object and context for which pg_stat_io reports NULL because
+// the operation can never happen.
+func (c *PGStatIOCollector) emitIfValid(ch chan<- prometheus.Metric, desc *prometheus.Desc, value sql.NullFloat64, labels []string) {
+	if !value.Valid {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(
+		desc,
+		prometheus.CounterValue,
+		value.Float64,
+		labels...,
+	)
+}
+
+// emitTimed emits the operation count, the time spent on the operations and
+// the average time per operation. Times are reported by pg_stat_io in
+// milliseconds.
+func (c *PGStatIOCollector) emitTimed(ch chan<- prometheus.Metric, opsDesc, timeDesc, latencyDesc *prometheus.Desc, ops, opTime sql.NullFloat64, labels []string) {
+	c.emitIfValid(ch, opsDesc, ops, labels)
+	if !opTime.Valid {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(
+		timeDesc,
+		prometheus.CounterValue,
+		opTime.Float64/1000.0,
+		labels...,
+	)
+	// Without track_io_timing the time stays at zero, which would make the
+	// latency look instantaneous.
+	if ops.Float64 > 0 && opTime.Float64 > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			latencyDesc,
+			prometheus.GaugeValue,
+			opTime.Float64/1000.0/ops.Float64,
+			labels...,
+		)
+	}
+}
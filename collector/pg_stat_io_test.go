@@ -0,0 +1,158 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGStatIOCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	columns := []string{"backend_type", "object", "context", "reads", "read_time", "writes", "write_time", "writebacks", "writeback_time", "read_bytes", "write_bytes", "extends", "extend_time", "hits", "evictions", "reuses", "fsyncs", "fsync_time"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("client backend", "relation", "normal", 100, 500, 10, 250, 0, 0, 819200, 81920, 30, 15, 900, 40, nil, 8, 2).
+		AddRow("autovacuum worker", "relation", "vacuum", 50, 0, 20, 0, nil, nil, 409600, 163840, 4, 0, 70, 5, 12, nil, nil).
+		AddRow("autovacuum worker", "temp relation", "vacuum", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mock.ExpectQuery(sanitizeQuery(statIOQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatIOCollector{
+			log: log.With(log.NewNopLogger(), "collector", "stat_io"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatIOCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 100},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 0.5},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_GAUGE, value: 0.005},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 10},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 0.25},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_GAUGE, value: 0.025},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 30},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 0.015},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_GAUGE, value: 0.0005},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 900},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 40},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 8},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_COUNTER, value: 0.002},
+		{labels: labelMap{"backend_type": "client backend", "object": "relation", "context": "normal"}, metricType: dto.MetricType_GAUGE, value: 0.00025},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 50},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 20},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 4},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 70},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 5},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 12},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 50},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 20},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 409600},
+		{labels: labelMap{"backend_type": "autovacuum worker", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 163840},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatIOCollectorPG18(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("18.0.0")}
+
+	columns := []string{"backend_type", "object", "context", "reads", "read_time", "writes", "write_time", "writebacks", "writeback_time", "read_bytes", "write_bytes", "extends", "extend_time", "hits", "evictions", "reuses", "fsyncs", "fsync_time"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("standalone backend", "relation", "vacuum", 5, 0, 2, 0, 0, 0, 40960, 16384, 1, 2, 3, 0, 1, 0, 0)
+	mock.ExpectQuery(sanitizeQuery(statIOQuery18)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatIOCollector{
+			log: log.With(log.NewNopLogger(), "collector", "stat_io"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatIOCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 5},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 2},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 1},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0.002},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_GAUGE, value: 0.002},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 3},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 1},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation", "context": "vacuum"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 5},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 2},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 40960},
+		{labels: labelMap{"backend_type": "standalone backend", "object": "relation"}, metricType: dto.MetricType_COUNTER, value: 16384},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
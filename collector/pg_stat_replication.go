@@ -0,0 +1,186 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statReplicationSubsystem = "stat_replication"
+
+func init() {
+	registerCollector(statReplicationSubsystem, defaultEnabled, NewPGStatReplicationCollector)
+}
+
+type PGStatReplicationCollector struct {
+	log log.Logger
+}
+
+func NewPGStatReplicationCollector(config collectorConfig) (Collector, error) {
+	return &PGStatReplicationCollector{log: config.logger}, nil
+}
+
+var (
+	// application_name and client_addr are not unique: standbys connecting
+	// over the Unix socket or from one host with the default walreceiver
+	// name only differ by the walsender pid.
+	statReplicationLabels = []string{"pid", "application_name", "client_addr", "state"}
+
+	statReplicationSentLagBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statReplicationSubsystem, "sent_lag_bytes"),
+		"Bytes of WAL generated on the primary that have not yet been sent to the standby",
+		statReplicationLabels,
+		prometheus.Labels{},
+	)
+	statReplicationWriteLagBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statReplicationSubsystem, "write_lag_bytes"),
+		"Bytes of WAL generated on the primary that the standby has not yet written to disk",
+		statReplicationLabels,
+		prometheus.Labels{},
+	)
+	statReplicationFlushLagBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statReplicationSubsystem, "flush_lag_bytes"),
+		"Bytes of WAL generated on the primary that the standby has not yet flushed to disk",
+		statReplicationLabels,
+		prometheus.Labels{},
+	)
+	statReplicationReplayLagBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statReplicationSubsystem, "replay_lag_bytes"),
+		"Bytes of WAL generated on the primary that the standby has not yet replayed",
+		statReplicationLabels,
+		prometheus.Labels{},
+	)
+	statReplicationWriteLagSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statReplicationSubsystem, "write_lag_seconds"),
+		"Time elapsed between flushing recent WAL locally and receiving notification that the standby has written it",
+		statReplicationLabels,
+		prometheus.Labels{},
+	)
+	statReplicationFlushLagSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statReplicationSubsystem, "flush_lag_seconds"),
+		"Time elapsed between flushing recent WAL locally and receiving notification that the standby has flushed it",
+		statReplicationLabels,
+		prometheus.Labels{},
+	)
+	statReplicationReplayLagSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statReplicationSubsystem, "replay_lag_seconds"),
+		"Time elapsed between flushing recent WAL locally and receiving notification that the standby has replayed it",
+		statReplicationLabels,
+		prometheus.Labels{},
+	)
+
+	// pg_current_wal_lsn() cannot be called during recovery, so the byte
+	// lag is left NULL for standbys cascading from a replica.
+	statReplicationQuery = `
+	SELECT
+		pid,
+		application_name,
+		COALESCE(host(client_addr), '') AS client_addr,
+		state,
+		CASE WHEN pg_is_in_recovery() THEN NULL ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), sent_lsn) END AS sent_lag_bytes,
+		CASE WHEN pg_is_in_recovery() THEN NULL ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), write_lsn) END AS write_lag_bytes,
+		CASE WHEN pg_is_in_recovery() THEN NULL ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), flush_lsn) END AS flush_lag_bytes,
+		CASE WHEN pg_is_in_recovery() THEN NULL ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn) END AS replay_lag_bytes,
+		EXTRACT(EPOCH FROM write_lag) AS write_lag_seconds,
+		EXTRACT(EPOCH FROM flush_lag) AS flush_lag_seconds,
+		EXTRACT(EPOCH FROM replay_lag) AS replay_lag_seconds
+	FROM pg_catalog.pg_stat_replication
+	`
+)
+
+func (c *PGStatReplicationCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// The *_lsn columns and the lag intervals were added in PostgreSQL 10
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_stat_replication lag columns are not available before PostgreSQL 10")
+		return nil
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		statReplicationQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pid sql.NullInt64
+		var applicationName, clientAddr, state sql.NullString
+		var sentLag, writeLag, flushLag, replayLag sql.NullFloat64
+		var writeLagSeconds, flushLagSeconds, replayLagSeconds sql.NullFloat64
+
+		if err := rows.Scan(
+			&pid,
+			&applicationName,
+			&clientAddr,
+			&state,
+			&sentLag,
+			&writeLag,
+			&flushLag,
+			&replayLag,
+			&writeLagSeconds,
+			&flushLagSeconds,
+			&replayLagSeconds,
+		); err != nil {
+			return err
+		}
+
+		stateLabel := "unknown"
+		if state.Valid {
+			stateLabel = state.String
+		}
+		pidLabel := "unknown"
+		if pid.Valid {
+			pidLabel = strconv.FormatInt(pid.Int64, 10)
+		}
+		labels := []string{pidLabel, applicationName.String, clientAddr.String, stateLabel}
+
+		// The lag intervals are NULL once the standby has caught up and
+		// is idle, and a LSN is NULL until the standby has reported it.
+		for _, lag := range []struct {
+			desc  *prometheus.Desc
+			value sql.NullFloat64
+		}{
+			{statReplicationSentLagBytes, sentLag},
+			{statReplicationWriteLagBytes, writeLag},
+			{statReplicationFlushLagBytes, flushLag},
+			{statReplicationReplayLagBytes, replayLag},
+			{statReplicationWriteLagSeconds, writeLagSeconds},
+			{statReplicationFlushLagSeconds, flushLagSeconds},
+			{statReplicationReplayLagSeconds, replayLagSeconds},
+		} {
+			if !lag.value.Valid {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(
+				lag.desc,
+				prometheus.GaugeValue,
+				lag.value.Float64,
+				labels...,
+			)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
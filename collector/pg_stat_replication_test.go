@@ -0,0 +1,123 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGStatReplicationCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("14.0.0")}
+
+	columns := []string{
+		"pid",
+		"application_name",
+		"client_addr",
+		"state",
+		"sent_lag_bytes",
+		"write_lag_bytes",
+		"flush_lag_bytes",
+		"replay_lag_bytes",
+		"write_lag_seconds",
+		"flush_lag_seconds",
+		"replay_lag_seconds",
+	}
+	rows := sqlmock.NewRows(columns).
+		AddRow(4242, "walreceiver", "10.0.0.2", "streaming", 0, 128, 256, 1024, 0.001, 0.002, 0.5).
+		AddRow(4243, "pg_basebackup", "", "backup", 4096, nil, nil, nil, nil, nil, nil).
+		AddRow(4244, "walreceiver", "", "streaming", 0, 0, 0, 0, nil, nil, nil)
+	mock.ExpectQuery(sanitizeQuery(statReplicationQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatReplicationCollector{
+			log: log.With(log.NewNopLogger(), "collector", "stat_replication"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatReplicationCollector.Update: %s", err)
+		}
+	}()
+
+	streaming := labelMap{"pid": "4242", "application_name": "walreceiver", "client_addr": "10.0.0.2", "state": "streaming"}
+	expected := []MetricResult{
+		{labels: streaming, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: streaming, metricType: dto.MetricType_GAUGE, value: 128},
+		{labels: streaming, metricType: dto.MetricType_GAUGE, value: 256},
+		{labels: streaming, metricType: dto.MetricType_GAUGE, value: 1024},
+		{labels: streaming, metricType: dto.MetricType_GAUGE, value: 0.001},
+		{labels: streaming, metricType: dto.MetricType_GAUGE, value: 0.002},
+		{labels: streaming, metricType: dto.MetricType_GAUGE, value: 0.5},
+		{labels: labelMap{"pid": "4243", "application_name": "pg_basebackup", "client_addr": "", "state": "backup"}, metricType: dto.MetricType_GAUGE, value: 4096},
+		// A second walreceiver over the Unix socket is told apart by its pid.
+		{labels: labelMap{"pid": "4244", "application_name": "walreceiver", "client_addr": "", "state": "streaming"}, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: labelMap{"pid": "4244", "application_name": "walreceiver", "client_addr": "", "state": "streaming"}, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: labelMap{"pid": "4244", "application_name": "walreceiver", "client_addr": "", "state": "streaming"}, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: labelMap{"pid": "4244", "application_name": "walreceiver", "client_addr": "", "state": "streaming"}, metricType: dto.MetricType_GAUGE, value: 0},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatReplicationCollectorPre10(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("9.6.0")}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatReplicationCollector{
+			log: log.With(log.NewNopLogger(), "collector", "stat_replication"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatReplicationCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No metrics before PostgreSQL 10", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
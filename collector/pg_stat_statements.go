@@ -16,9 +16,16 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/blang/semver/v4"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -31,12 +38,73 @@ func init() {
 	registerCollector(statStatementsSubsystem, defaultDisabled, NewPGStatStatementsCollector)
 }
 
+var statStatementsLimit = kingpin.Flag("collector.stat_statements.limit", "Maximum number of statements to report, highest total execution time first.").Default("100").Int()
+
 type PGStatStatementsCollector struct {
-	log log.Logger
+	log       log.Logger
+	databases databaseFilter
+	limit     int
+	// meanTimes remembers the mean execution time of each statement seen
+	// by the previous scrape of each target. When nil no deltas are
+	// exported.
+	meanTimes *statStatementsMeanTimes
 }
 
 func NewPGStatStatementsCollector(config collectorConfig) (Collector, error) {
-	return &PGStatStatementsCollector{log: config.logger}, nil
+	return &PGStatStatementsCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		limit:     *statStatementsLimit,
+		meanTimes: &statStatementsMeanTimes{},
+	}, nil
+}
+
+// statStatementsMeanTimesExpiry is how long the state of a target that is
+// no longer scraped is kept, so that probing many different targets does not
+// grow the state without bound.
+const statStatementsMeanTimesExpiry = time.Hour
+
+// statStatementsMeanTimes holds, for every scraped target, the mean
+// execution time of the statements reported by its last successful scrape.
+// The collector instance is shared by /metrics and all /probe targets, so the
+// state is kept per DSN. A target's state is replaced as a whole on every
+// scrape of that target, so statements that dropped out of the top
+// --collector.stat_statements.limit are forgotten and it never holds more
+// entries than that limit.
+type statStatementsMeanTimes struct {
+	mu      sync.Mutex
+	targets map[string]*statStatementsTargetMeanTimes
+}
+
+type statStatementsTargetMeanTimes struct {
+	previous   map[statStatementsKey]float64
+	lastScrape time.Time
+}
+
+type statStatementsKey struct {
+	user, datname, queryid string
+}
+
+// swap stores current as the state of target for its next scrape and returns
+// the state of its previous one. Targets not scraped for
+// statStatementsMeanTimesExpiry are dropped.
+func (s *statStatementsMeanTimes) swap(target string, current map[statStatementsKey]float64, now time.Time) map[statStatementsKey]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.targets == nil {
+		s.targets = map[string]*statStatementsTargetMeanTimes{}
+	}
+	var previous map[statStatementsKey]float64
+	if state, ok := s.targets[target]; ok {
+		previous = state.previous
+	}
+	s.targets[target] = &statStatementsTargetMeanTimes{previous: current, lastScrape: now}
+	for t, state := range s.targets {
+		if now.Sub(state.lastScrape) > statStatementsMeanTimesExpiry {
+			delete(s.targets, t)
+		}
+	}
+	return previous
 }
 
 var (
@@ -70,6 +138,60 @@ var (
 		[]string{"user", "datname", "queryid"},
 		prometheus.Labels{},
 	)
+	statStatementsMeanSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "mean_seconds"),
+		"Mean time spent in the statement, in seconds",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsMeanSecondsDelta = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "mean_seconds_delta"),
+		"Change of the mean time spent in the statement since the previous scrape, in seconds. A sudden jump can point to a plan regression",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsSharedBlocksHitTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "shared_blocks_hit_total"),
+		"Total number of shared block cache hits by the statement",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsSharedBlocksReadTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "shared_blocks_read_total"),
+		"Total number of shared blocks read by the statement",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsSharedBlocksDirtiedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "shared_blocks_dirtied_total"),
+		"Total number of shared blocks dirtied by the statement",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsSharedBlocksWrittenTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "shared_blocks_written_total"),
+		"Total number of shared blocks written by the statement",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsRowsPerCall = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "rows_per_call"),
+		"Average number of rows retrieved or affected per execution of the statement",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsPlanGenerationRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "plan_generation_ratio"),
+		"Number of times the statement was planned divided by the number of times it was executed. A value near 1 means plans are not being reused. Requires pg_stat_statements.track_planning",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	statStatementsDeallocTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "dealloc_total"),
+		"Number of times pg_stat_statements entries were deallocated because more distinct statements than pg_stat_statements.max were observed",
+		[]string{},
+		prometheus.Labels{},
+	)
 
 	pgStatStatementsQuery = `SELECT
 		pg_get_userbyid(userid) as user,
@@ -79,7 +201,12 @@ var (
 		pg_stat_statements.total_time / 1000.0 as seconds_total,
 		pg_stat_statements.rows as rows_total,
 		pg_stat_statements.blk_read_time / 1000.0 as block_read_seconds_total,
-		pg_stat_statements.blk_write_time / 1000.0 as block_write_seconds_total
+		pg_stat_statements.blk_write_time / 1000.0 as block_write_seconds_total,
+		pg_stat_statements.mean_time / 1000.0 as mean_seconds,
+		pg_stat_statements.shared_blks_hit as shared_blocks_hit_total,
+		pg_stat_statements.shared_blks_read as shared_blocks_read_total,
+		pg_stat_statements.shared_blks_dirtied as shared_blocks_dirtied_total,
+		pg_stat_statements.shared_blks_written as shared_blocks_written_total
 		FROM pg_stat_statements
 	JOIN pg_database
 		ON pg_database.oid = pg_stat_statements.dbid
@@ -88,9 +215,9 @@ var (
 		SELECT percentile_cont(0.1)
 			WITHIN GROUP (ORDER BY total_time)
 			FROM pg_stat_statements
-		)
+		)%s
 	ORDER BY seconds_total DESC
-	LIMIT 100;`
+	LIMIT $1;`
 
 	pgStatStatementsNewQuery = `SELECT
 		pg_get_userbyid(userid) as user,
@@ -100,7 +227,13 @@ var (
 		pg_stat_statements.total_exec_time / 1000.0 as seconds_total,
 		pg_stat_statements.rows as rows_total,
 		pg_stat_statements.blk_read_time / 1000.0 as block_read_seconds_total,
-		pg_stat_statements.blk_write_time / 1000.0 as block_write_seconds_total
+		pg_stat_statements.blk_write_time / 1000.0 as block_write_seconds_total,
+		pg_stat_statements.mean_exec_time / 1000.0 as mean_seconds,
+		pg_stat_statements.shared_blks_hit as shared_blocks_hit_total,
+		pg_stat_statements.shared_blks_read as shared_blocks_read_total,
+		pg_stat_statements.shared_blks_dirtied as shared_blocks_dirtied_total,
+		pg_stat_statements.shared_blks_written as shared_blocks_written_total,
+		pg_stat_statements.plans as plans_total
 		FROM pg_stat_statements
 	JOIN pg_database
 		ON pg_database.oid = pg_stat_statements.dbid
@@ -109,30 +242,57 @@ var (
 		SELECT percentile_cont(0.1)
 			WITHIN GROUP (ORDER BY total_exec_time)
 			FROM pg_stat_statements
-		)
+		)%s
 	ORDER BY seconds_total DESC
-	LIMIT 100;`
+	LIMIT $1;`
+
+	pgStatStatementsInfoQuery = `SELECT dealloc FROM pg_stat_statements_info;`
+
+	pgStatStatementsExtensionQuery = `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements');`
 )
 
-func (PGStatStatementsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+// pqUndefinedTable is the SQLSTATE of errors about missing relations.
+const pqUndefinedTable = "42P01"
+
+func (c PGStatStatementsCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	var extensionInstalled bool
+	if err := db.QueryRowContext(ctx, pgStatStatementsExtensionQuery).Scan(&extensionInstalled); err != nil {
+		return err
+	}
+	if !extensionInstalled {
+		level.Debug(c.log).Log("msg", "pg_stat_statements extension is not installed")
+		return nil
+	}
+
 	query := pgStatStatementsQuery
-	if instance.version.GE(semver.MustParse("13.0.0")) {
+	// The plans column was added in PostgreSQL 13
+	plansAvail := instance.version.GE(semver.MustParse("13.0.0"))
+	if plansAvail {
 		query = pgStatStatementsNewQuery
 	}
 
-	db := instance.getDB()
-	rows, err := db.QueryContext(ctx, query)
+	databases, args := c.databases.and("pg_database.datname", []any{c.limit})
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(query, databases), args...)
 
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
+	meanSecondsByKey := map[statStatementsKey]float64{}
+	var keys []statStatementsKey
 	for rows.Next() {
 		var user, datname, queryid sql.NullString
-		var callsTotal, rowsTotal sql.NullInt64
-		var secondsTotal, blockReadSecondsTotal, blockWriteSecondsTotal sql.NullFloat64
+		var callsTotal, rowsTotal, plansTotal sql.NullInt64
+		var secondsTotal, blockReadSecondsTotal, blockWriteSecondsTotal, meanSeconds sql.NullFloat64
+		var sharedBlocksHitTotal, sharedBlocksReadTotal, sharedBlocksDirtiedTotal, sharedBlocksWrittenTotal sql.NullInt64
 
-		if err := rows.Scan(&user, &datname, &queryid, &callsTotal, &secondsTotal, &rowsTotal, &blockReadSecondsTotal, &blockWriteSecondsTotal); err != nil {
+		r := []any{&user, &datname, &queryid, &callsTotal, &secondsTotal, &rowsTotal, &blockReadSecondsTotal, &blockWriteSecondsTotal,
+			&meanSeconds, &sharedBlocksHitTotal, &sharedBlocksReadTotal, &sharedBlocksDirtiedTotal, &sharedBlocksWrittenTotal}
+		if plansAvail {
+			r = append(r, &plansTotal)
+		}
+		if err := rows.Scan(r...); err != nil {
 			return err
 		}
 
@@ -203,9 +363,97 @@ func (PGStatStatementsCollector) Update(ctx context.Context, instance *instance,
 			blockWriteSecondsTotalMetric,
 			userLabel, datnameLabel, queryidLabel,
 		)
+
+		if rowsTotal.Valid && callsTotal.Valid && callsTotal.Int64 > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				statStatementsRowsPerCall,
+				prometheus.GaugeValue,
+				float64(rowsTotal.Int64)/float64(callsTotal.Int64),
+				userLabel, datnameLabel, queryidLabel,
+			)
+		}
+
+		if plansAvail && plansTotal.Valid && callsTotal.Valid && callsTotal.Int64 > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				statStatementsPlanGenerationRatio,
+				prometheus.GaugeValue,
+				float64(plansTotal.Int64)/float64(callsTotal.Int64),
+				userLabel, datnameLabel, queryidLabel,
+			)
+		}
+
+		if meanSeconds.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				statStatementsMeanSeconds,
+				prometheus.GaugeValue,
+				meanSeconds.Float64,
+				userLabel, datnameLabel, queryidLabel,
+			)
+			key := statStatementsKey{userLabel, datnameLabel, queryidLabel}
+			meanSecondsByKey[key] = meanSeconds.Float64
+			keys = append(keys, key)
+		}
+
+		for _, blocks := range []struct {
+			desc  *prometheus.Desc
+			value sql.NullInt64
+		}{
+			{statStatementsSharedBlocksHitTotal, sharedBlocksHitTotal},
+			{statStatementsSharedBlocksReadTotal, sharedBlocksReadTotal},
+			{statStatementsSharedBlocksDirtiedTotal, sharedBlocksDirtiedTotal},
+			{statStatementsSharedBlocksWrittenTotal, sharedBlocksWrittenTotal},
+		} {
+			ch <- prometheus.MustNewConstMetric(
+				blocks.desc,
+				prometheus.CounterValue,
+				float64(blocks.value.Int64),
+				userLabel, datnameLabel, queryidLabel,
+			)
+		}
 	}
 	if err := rows.Err(); err != nil {
 		return err
 	}
+
+	if c.meanTimes != nil {
+		previous := c.meanTimes.swap(instance.dsn, meanSecondsByKey, time.Now())
+		for _, key := range keys {
+			current := meanSecondsByKey[key]
+			last, ok := previous[key]
+			if !ok {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(
+				statStatementsMeanSecondsDelta,
+				prometheus.GaugeValue,
+				current-last,
+				key.user, key.datname, key.queryid,
+			)
+		}
+	}
+
+	// pg_stat_statements_info was added in PostgreSQL 14, but only exists
+	// once the extension is updated to 1.9, which pg_upgrade does not do.
+	if !instance.version.GE(semver.MustParse("14.0.0")) {
+		return nil
+	}
+	var dealloc sql.NullInt64
+	if err := db.QueryRowContext(ctx, pgStatStatementsInfoQuery).Scan(&dealloc); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqUndefinedTable {
+			level.Debug(c.log).Log("msg", "pg_stat_statements_info is not available, update the pg_stat_statements extension to 1.9 or later", "err", err)
+			return nil
+		}
+		return err
+	}
+	deallocMetric := 0.0
+	if dealloc.Valid {
+		deallocMetric = float64(dealloc.Int64)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		statStatementsDeallocTotal,
+		prometheus.CounterValue,
+		deallocMetric,
+	)
 	return nil
 }
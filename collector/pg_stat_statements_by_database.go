@@ -0,0 +1,137 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statStatementsByDatabaseSubsystem = "stat_statements_by_database"
+
+func init() {
+	// Requires the pg_stat_statements extension, so it is disabled by default
+	// like the stat_statements collector.
+	registerCollector(statStatementsByDatabaseSubsystem, defaultDisabled, NewPGStatStatementsByDatabaseCollector)
+}
+
+// PGStatStatementsByDatabaseCollector sums the WAL generated by the
+// statements in pg_stat_statements per database, attributing WAL volume and
+// the replication load it causes to specific databases.
+type PGStatStatementsByDatabaseCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGStatStatementsByDatabaseCollector(config collectorConfig) (Collector, error) {
+	return &PGStatStatementsByDatabaseCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	statStatementsDatabaseWALRecords = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "database_wal_records"),
+		"Number of WAL records generated by the statements of the database. This is a sum over the statements pg_stat_statements currently tracks and drops when entries are evicted, see pg_stat_statements_dealloc_total",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
+	statStatementsDatabaseWALFPI = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "database_wal_fpi"),
+		"Number of WAL full page images generated by the statements of the database. This is a sum over the statements pg_stat_statements currently tracks and drops when entries are evicted, see pg_stat_statements_dealloc_total",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
+	statStatementsDatabaseWALBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "database_wal_bytes"),
+		"Amount of WAL generated by the statements of the database, in bytes. This is a sum over the statements pg_stat_statements currently tracks and drops when entries are evicted, see pg_stat_statements_dealloc_total",
+		[]string{"datname"},
+		prometheus.Labels{},
+	)
+
+	statStatementsByDatabaseQuery = `SELECT
+		d.datname,
+		sum(s.wal_records) AS wal_records,
+		sum(s.wal_fpi) AS wal_fpi,
+		sum(s.wal_bytes) AS wal_bytes
+	FROM pg_stat_statements s
+	LEFT JOIN pg_database d ON d.oid = s.dbid
+	GROUP BY d.datname`
+)
+
+func (c PGStatStatementsByDatabaseCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// The wal_* columns were added to pg_stat_statements in PostgreSQL 13
+	if instance.version.LT(semver.MustParse("13.0.0")) {
+		level.Debug(c.log).Log("msg", "stat_statements_by_database collector is not available on PostgreSQL < 13, skipping")
+		return nil
+	}
+
+	db := instance.getDB()
+	var extensionInstalled bool
+	if err := db.QueryRowContext(ctx, pgStatStatementsExtensionQuery).Scan(&extensionInstalled); err != nil {
+		return err
+	}
+	if !extensionInstalled {
+		level.Debug(c.log).Log("msg", "pg_stat_statements extension is not installed")
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx, statStatementsByDatabaseQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname sql.NullString
+		var walRecords, walFPI, walBytes sql.NullFloat64
+		if err := rows.Scan(&datname, &walRecords, &walFPI, &walBytes); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			statStatementsDatabaseWALRecords,
+			prometheus.GaugeValue,
+			walRecords.Float64,
+			datnameLabel,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			statStatementsDatabaseWALFPI,
+			prometheus.GaugeValue,
+			walFPI.Float64,
+			datnameLabel,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			statStatementsDatabaseWALBytes,
+			prometheus.GaugeValue,
+			walBytes.Float64,
+			datnameLabel,
+		)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statStatementsByUserSubsystem = "stat_statements_by_user"
+
+func init() {
+	// Requires the pg_stat_statements extension, so it is disabled by default
+	// like the stat_statements collector.
+	registerCollector(statStatementsByUserSubsystem, defaultDisabled, NewPGStatStatementsByUserCollector)
+}
+
+// PGStatStatementsByUserCollector sums the pg_stat_statements execution time
+// and calls per role, attributing query time to the services connecting as
+// each role.
+type PGStatStatementsByUserCollector struct {
+	log log.Logger
+}
+
+func NewPGStatStatementsByUserCollector(config collectorConfig) (Collector, error) {
+	return &PGStatStatementsByUserCollector{log: config.logger}, nil
+}
+
+var (
+	statStatementsUserExecSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "user_exec_seconds"),
+		"Time spent executing the statements of the role, in seconds. This is a sum over the statements pg_stat_statements currently tracks and drops when entries are evicted, see pg_stat_statements_dealloc_total",
+		[]string{"user"},
+		prometheus.Labels{},
+	)
+	statStatementsUserCalls = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statStatementsSubsystem, "user_calls"),
+		"Number of executions of the statements of the role. This is a sum over the statements pg_stat_statements currently tracks and drops when entries are evicted, see pg_stat_statements_dealloc_total",
+		[]string{"user"},
+		prometheus.Labels{},
+	)
+
+	statStatementsByUserQuery = `SELECT
+		r.rolname AS user,
+		sum(s.calls) AS calls,
+		sum(s.total_time) / 1000.0 AS exec_seconds
+	FROM pg_stat_statements s
+	LEFT JOIN pg_roles r ON r.oid = s.userid
+	GROUP BY r.rolname`
+
+	// PostgreSQL 13 renamed total_time to total_exec_time when the planning
+	// time started being tracked separately.
+	statStatementsByUserQuery13 = `SELECT
+		r.rolname AS user,
+		sum(s.calls) AS calls,
+		sum(s.total_exec_time) / 1000.0 AS exec_seconds
+	FROM pg_stat_statements s
+	LEFT JOIN pg_roles r ON r.oid = s.userid
+	GROUP BY r.rolname`
+)
+
+func (c PGStatStatementsByUserCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	var extensionInstalled bool
+	if err := db.QueryRowContext(ctx, pgStatStatementsExtensionQuery).Scan(&extensionInstalled); err != nil {
+		return err
+	}
+	if !extensionInstalled {
+		level.Debug(c.log).Log("msg", "pg_stat_statements extension is not installed")
+		return nil
+	}
+
+	query := statStatementsByUserQuery
+	if instance.version.GE(semver.MustParse("13.0.0")) {
+		query = statStatementsByUserQuery13
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user sql.NullString
+		var calls, execSeconds sql.NullFloat64
+		if err := rows.Scan(&user, &calls, &execSeconds); err != nil {
+			return err
+		}
+
+		userLabel := "unknown"
+		if user.Valid {
+			userLabel = user.String
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			statStatementsUserExecSeconds,
+			prometheus.GaugeValue,
+			execSeconds.Float64,
+			userLabel,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			statStatementsUserCalls,
+			prometheus.GaugeValue,
+			calls.Float64,
+			userLabel,
+		)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGStatStatementsByUserCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("14.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	columns := []string{"user", "calls", "exec_seconds"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("orders_api", 12000, 823.5).
+		AddRow(nil, 4, 0.25)
+	mock.ExpectQuery(sanitizeQuery(statStatementsByUserQuery13)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatStatementsByUserCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatStatementsByUserCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"user": "orders_api"}, metricType: dto.MetricType_GAUGE, value: 823.5},
+		{labels: labelMap{"user": "orders_api"}, metricType: dto.MetricType_GAUGE, value: 12000},
+		{labels: labelMap{"user": "unknown"}, metricType: dto.MetricType_GAUGE, value: 0.25},
+		{labels: labelMap{"user": "unknown"}, metricType: dto.MetricType_GAUGE, value: 4},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatStatementsByUserCollectorPre13(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("12.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	columns := []string{"user", "calls", "exec_seconds"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("postgres", 10, 1.5)
+	mock.ExpectQuery(sanitizeQuery(statStatementsByUserQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatStatementsByUserCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatStatementsByUserCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"user": "postgres"}, metricType: dto.MetricType_GAUGE, value: 1.5},
+		{labels: labelMap{"user": "postgres"}, metricType: dto.MetricType_GAUGE, value: 10},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatStatementsByUserCollectorNoExtension(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("14.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatStatementsByUserCollector{log: log.NewNopLogger()}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatStatementsByUserCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No metrics without the extension", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -14,10 +14,14 @@ package collector
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
@@ -32,10 +36,11 @@ func TestPGStateStatementsCollector(t *testing.T) {
 
 	inst := &instance{db: db, version: semver.MustParse("12.0.0")}
 
-	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total"}
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total"}
 	rows := sqlmock.NewRows(columns).
-		AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2)
-	mock.ExpectQuery(sanitizeQuery(pgStatStatementsQuery)).WillReturnRows(rows)
+		AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2, 0.08, 10, 11, 12, 13)
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsQuery, ""))).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
 	go func() {
@@ -53,6 +58,12 @@ func TestPGStateStatementsCollector(t *testing.T) {
 		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 100},
 		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 0.1},
 		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 0.2},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 20},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.08},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 10},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 11},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 12},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 13},
 	}
 
 	convey.Convey("Metrics comparison", t, func() {
@@ -75,10 +86,11 @@ func TestPGStateStatementsCollectorNull(t *testing.T) {
 
 	inst := &instance{db: db, version: semver.MustParse("13.3.7")}
 
-	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total"}
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total", "plans_total"}
 	rows := sqlmock.NewRows(columns).
-		AddRow(nil, nil, nil, nil, nil, nil, nil, nil)
-	mock.ExpectQuery(sanitizeQuery(pgStatStatementsNewQuery)).WillReturnRows(rows)
+		AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsNewQuery, ""))).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
 	go func() {
@@ -96,6 +108,10 @@ func TestPGStateStatementsCollectorNull(t *testing.T) {
 		{labels: labelMap{"user": "unknown", "datname": "unknown", "queryid": "unknown"}, metricType: dto.MetricType_COUNTER, value: 0},
 		{labels: labelMap{"user": "unknown", "datname": "unknown", "queryid": "unknown"}, metricType: dto.MetricType_COUNTER, value: 0},
 		{labels: labelMap{"user": "unknown", "datname": "unknown", "queryid": "unknown"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"user": "unknown", "datname": "unknown", "queryid": "unknown"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"user": "unknown", "datname": "unknown", "queryid": "unknown"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"user": "unknown", "datname": "unknown", "queryid": "unknown"}, metricType: dto.MetricType_COUNTER, value: 0},
+		{labels: labelMap{"user": "unknown", "datname": "unknown", "queryid": "unknown"}, metricType: dto.MetricType_COUNTER, value: 0},
 	}
 
 	convey.Convey("Metrics comparison", t, func() {
@@ -118,10 +134,63 @@ func TestPGStateStatementsCollectorNewPG(t *testing.T) {
 
 	inst := &instance{db: db, version: semver.MustParse("13.3.7")}
 
-	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total"}
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total", "plans_total"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2, 0.08, 10, 11, 12, 13, 4)
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsNewQuery, ""))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatStatementsCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatStatementsCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 5},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 0.4},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 100},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 0.1},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 0.2},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 20},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.8},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.08},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 10},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 11},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 12},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 13},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStateStatementsCollectorDealloc(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("14.0.0")}
+
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total", "plans_total"}
 	rows := sqlmock.NewRows(columns).
-		AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2)
-	mock.ExpectQuery(sanitizeQuery(pgStatStatementsNewQuery)).WillReturnRows(rows)
+		AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2, 0.08, 10, 11, 12, 13, 4)
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsNewQuery, ""))).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsInfoQuery)).WillReturnRows(sqlmock.NewRows([]string{"dealloc"}).AddRow(42))
 
 	ch := make(chan prometheus.Metric)
 	go func() {
@@ -139,6 +208,14 @@ func TestPGStateStatementsCollectorNewPG(t *testing.T) {
 		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 100},
 		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 0.1},
 		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 0.2},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 20},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.8},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.08},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 10},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 11},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 12},
+		{labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 13},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 42},
 	}
 
 	convey.Convey("Metrics comparison", t, func() {
@@ -151,3 +228,234 @@ func TestPGStateStatementsCollectorNewPG(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestPGStateStatementsCollectorNoInfoView(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("14.0.0")}
+
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total", "plans_total"}
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsNewQuery, ""))).WillReturnRows(sqlmock.NewRows(columns))
+	// An extension older than 1.9 on PostgreSQL 14 has no pg_stat_statements_info.
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsInfoQuery)).WillReturnError(&pq.Error{Code: pqUndefinedTable, Message: `relation "pg_stat_statements_info" does not exist`})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatStatementsCollector{log: log.NewNopLogger()}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatStatementsCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No dealloc metric without pg_stat_statements_info", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStateStatementsCollectorLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("13.3.7")}
+
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total", "plans_total"}
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsNewQuery, ""))).WithArgs(25).WillReturnRows(sqlmock.NewRows(columns))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatStatementsCollector{limit: 25}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatStatementsCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No metrics without statements", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStateStatementsCollectorNoExtension(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatStatementsCollector{
+			log: log.With(log.NewNopLogger(), "collector", "stat_statements"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatStatementsCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No metrics without the extension", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStateStatementsCollectorMeanSecondsDelta(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("12.0.0")}
+
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total"}
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsQuery, ""))).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2, 0.25, 10, 11, 12, 13).
+		AddRow("postgres", "postgres", 1501, 5, 0.4, 100, 0.1, 0.2, 0.5, 10, 11, 12, 13))
+	mock.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsQuery, ""))).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("postgres", "postgres", 1500, 6, 1.4, 101, 0.1, 0.2, 1.0, 10, 11, 12, 13))
+
+	c := PGStatStatementsCollector{meanTimes: &statStatementsMeanTimes{}}
+	scrape := func() []prometheus.Metric {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			defer close(ch)
+			if err := c.Update(context.Background(), inst, ch); err != nil {
+				t.Errorf("Error calling PGStatStatementsCollector.Update: %s", err)
+			}
+		}()
+		var metrics []prometheus.Metric
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		return metrics
+	}
+
+	// 11 metrics per statement, no delta without a previous scrape.
+	first := scrape()
+	second := scrape()
+
+	convey.Convey("Mean seconds delta", t, func() {
+		convey.So(first, convey.ShouldHaveLength, 22)
+		convey.So(second, convey.ShouldHaveLength, 12)
+		convey.So(second[11].Desc(), convey.ShouldEqual, statStatementsMeanSecondsDelta)
+		convey.So(readMetric(second[11]), convey.ShouldResemble, MetricResult{
+			labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.75,
+		})
+		// Statements missing from the latest scrape are evicted.
+		convey.So(c.meanTimes.targets[inst.dsn].previous, convey.ShouldHaveLength, 1)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStateStatementsCollectorMeanSecondsDeltaPerTarget(t *testing.T) {
+	columns := []string{"user", "datname", "queryid", "calls_total", "seconds_total", "rows_total", "block_read_seconds_total", "block_write_seconds_total", "mean_seconds", "shared_blocks_hit_total", "shared_blocks_read_total", "shared_blocks_dirtied_total", "shared_blocks_written_total"}
+
+	dbA, mockA, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer dbA.Close()
+	dbB, mockB, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer dbB.Close()
+
+	instA := &instance{dsn: "host=a", db: dbA, version: semver.MustParse("12.0.0")}
+	instB := &instance{dsn: "host=b", db: dbB, version: semver.MustParse("12.0.0")}
+
+	// Both targets report the same statement with different mean times.
+	for _, mean := range []float64{0.25, 1.0} {
+		mockA.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mockA.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsQuery, ""))).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2, mean, 10, 11, 12, 13))
+	}
+	mockB.ExpectQuery(sanitizeQuery(pgStatStatementsExtensionQuery)).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mockB.ExpectQuery(sanitizeQuery(fmt.Sprintf(pgStatStatementsQuery, ""))).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("postgres", "postgres", 1500, 5, 0.4, 100, 0.1, 0.2, 8.0, 10, 11, 12, 13))
+
+	c := PGStatStatementsCollector{meanTimes: &statStatementsMeanTimes{}}
+	scrape := func(inst *instance) []prometheus.Metric {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			defer close(ch)
+			if err := c.Update(context.Background(), inst, ch); err != nil {
+				t.Errorf("Error calling PGStatStatementsCollector.Update: %s", err)
+			}
+		}()
+		var metrics []prometheus.Metric
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		return metrics
+	}
+
+	first := scrape(instA)
+	other := scrape(instB)
+	second := scrape(instA)
+
+	convey.Convey("Mean seconds delta is computed per target", t, func() {
+		convey.So(first, convey.ShouldHaveLength, 11)
+		convey.So(other, convey.ShouldHaveLength, 11)
+		convey.So(second, convey.ShouldHaveLength, 12)
+		convey.So(readMetric(second[11]), convey.ShouldResemble, MetricResult{
+			labels: labelMap{"user": "postgres", "datname": "postgres", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.75,
+		})
+		convey.So(c.meanTimes.targets, convey.ShouldHaveLength, 2)
+	})
+	if err := mockA.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+	if err := mockB.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestStatStatementsMeanTimesExpiry(t *testing.T) {
+	s := &statStatementsMeanTimes{}
+	now := time.Now()
+	key := statStatementsKey{"postgres", "postgres", "1500"}
+
+	s.swap("host=a", map[statStatementsKey]float64{key: 1}, now)
+	s.swap("host=b", map[statStatementsKey]float64{key: 2}, now.Add(statStatementsMeanTimesExpiry+time.Second))
+
+	if _, ok := s.targets["host=a"]; ok {
+		t.Errorf("expected state of a target not scraped for %s to be dropped", statStatementsMeanTimesExpiry)
+	}
+	if len(s.targets) != 1 {
+		t.Errorf("expected 1 target, got %d", len(s.targets))
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statSubscriptionSubsystem = "stat_subscription"
+
+func init() {
+	registerCollector(statSubscriptionSubsystem, defaultDisabled, NewPGStatSubscriptionCollector)
+}
+
+// PGStatSubscriptionCollector exposes the progress of the logical replication
+// workers running on a subscriber from pg_stat_subscription and, on
+// PostgreSQL 15+, the error counts from pg_stat_subscription_stats. Whether
+// subscriptions are enabled at all is reported by the subscription
+// collector.
+type PGStatSubscriptionCollector struct {
+	log log.Logger
+}
+
+func NewPGStatSubscriptionCollector(config collectorConfig) (Collector, error) {
+	return &PGStatSubscriptionCollector{log: config.logger}, nil
+}
+
+var (
+	statSubscriptionLastMsgSendTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statSubscriptionSubsystem, "last_msg_send_time_seconds"),
+		"Send time of the last message received from the publisher, as a Unix timestamp",
+		[]string{"subname", "pid"},
+		prometheus.Labels{},
+	)
+	statSubscriptionLastMsgReceiptTime = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statSubscriptionSubsystem, "last_msg_receipt_time_seconds"),
+		"Receipt time of the last message received from the publisher, as a Unix timestamp",
+		[]string{"subname", "pid"},
+		prometheus.Labels{},
+	)
+	statSubscriptionSecondsSinceLastMsg = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statSubscriptionSubsystem, "seconds_since_last_msg"),
+		"Seconds since the last message was received from the publisher",
+		[]string{"subname", "pid"},
+		prometheus.Labels{},
+	)
+	statSubscriptionLatestEndLsnLag = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statSubscriptionSubsystem, "latest_end_lsn_lag_bytes"),
+		"Bytes of WAL received but not yet reported back to the publisher (received_lsn - latest_end_lsn)",
+		[]string{"subname", "pid"},
+		prometheus.Labels{},
+	)
+	statSubscriptionApplyErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statSubscriptionSubsystem, "apply_errors_total"),
+		"Number of times an error occurred while applying changes",
+		[]string{"subname"},
+		prometheus.Labels{},
+	)
+	statSubscriptionSyncErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statSubscriptionSubsystem, "sync_errors_total"),
+		"Number of times an error occurred during the initial table synchronization",
+		[]string{"subname"},
+		prometheus.Labels{},
+	)
+
+	statSubscriptionQuery = `
+	SELECT
+		subname,
+		pid,
+		EXTRACT(EPOCH FROM last_msg_send_time) AS last_msg_send_time,
+		EXTRACT(EPOCH FROM last_msg_receipt_time) AS last_msg_receipt_time,
+		EXTRACT(EPOCH FROM now() - last_msg_receipt_time) AS seconds_since_last_msg,
+		pg_wal_lsn_diff(received_lsn, latest_end_lsn) AS latest_end_lsn_lag
+	FROM pg_catalog.pg_stat_subscription
+	WHERE pid IS NOT NULL
+	`
+
+	statSubscriptionStatsQuery = `
+	SELECT
+		subname,
+		apply_error_count,
+		sync_error_count
+	FROM pg_catalog.pg_stat_subscription_stats
+	`
+)
+
+func (c *PGStatSubscriptionCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// Logical replication subscriptions were added in PostgreSQL 10
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		level.Debug(c.log).Log("msg", "stat_subscription collector is not available on PostgreSQL < 10, skipping")
+		return nil
+	}
+
+	db := instance.getDB()
+	if err := c.updateWorkers(ctx, db, ch); err != nil {
+		return err
+	}
+
+	// pg_stat_subscription_stats was added in PostgreSQL 15
+	if instance.version.LT(semver.MustParse("15.0.0")) {
+		return nil
+	}
+	return c.updateStats(ctx, db, ch)
+}
+
+func (c *PGStatSubscriptionCollector) updateWorkers(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, statSubscriptionQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subname sql.NullString
+		var pid sql.NullInt64
+		var lastMsgSendTime, lastMsgReceiptTime, secondsSinceLastMsg, latestEndLsnLag sql.NullFloat64
+		if err := rows.Scan(&subname, &pid, &lastMsgSendTime, &lastMsgReceiptTime, &secondsSinceLastMsg, &latestEndLsnLag); err != nil {
+			return err
+		}
+		if !subname.Valid {
+			continue
+		}
+		pidLabel := "unknown"
+		if pid.Valid {
+			pidLabel = strconv.FormatInt(pid.Int64, 10)
+		}
+
+		// A worker that has not received anything yet reports NULL times
+		// and LSNs; leave those metrics out rather than exporting zero.
+		if lastMsgSendTime.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				statSubscriptionLastMsgSendTime,
+				prometheus.GaugeValue,
+				lastMsgSendTime.Float64,
+				subname.String, pidLabel,
+			)
+		}
+		if lastMsgReceiptTime.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				statSubscriptionLastMsgReceiptTime,
+				prometheus.GaugeValue,
+				lastMsgReceiptTime.Float64,
+				subname.String, pidLabel,
+			)
+		}
+		if secondsSinceLastMsg.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				statSubscriptionSecondsSinceLastMsg,
+				prometheus.GaugeValue,
+				secondsSinceLastMsg.Float64,
+				subname.String, pidLabel,
+			)
+		}
+		if latestEndLsnLag.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				statSubscriptionLatestEndLsnLag,
+				prometheus.GaugeValue,
+				latestEndLsnLag.Float64,
+				subname.String, pidLabel,
+			)
+		}
+	}
+	return rows.Err()
+}
+
+func (c *PGStatSubscriptionCollector) updateStats(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, statSubscriptionStatsQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subname sql.NullString
+		var applyErrors, syncErrors sql.NullInt64
+		if err := rows.Scan(&subname, &applyErrors, &syncErrors); err != nil {
+			return err
+		}
+		if !subname.Valid {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			statSubscriptionApplyErrors,
+			prometheus.CounterValue,
+			float64(applyErrors.Int64),
+			subname.String,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			statSubscriptionSyncErrors,
+			prometheus.CounterValue,
+			float64(syncErrors.Int64),
+			subname.String,
+		)
+	}
+	return rows.Err()
+}
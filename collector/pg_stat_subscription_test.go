@@ -0,0 +1,120 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGStatSubscriptionCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	columns := []string{"subname", "pid", "last_msg_send_time", "last_msg_receipt_time", "seconds_since_last_msg", "latest_end_lsn_lag"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("warehouse", 4242, 1700000000.25, 1700000000.5, 12.5, 8192).
+		AddRow("warehouse", 4243, nil, nil, nil, nil)
+	mock.ExpectQuery(sanitizeQuery(statSubscriptionQuery)).WillReturnRows(rows)
+
+	statsRows := sqlmock.NewRows([]string{"subname", "apply_error_count", "sync_error_count"}).
+		AddRow("warehouse", 3, 1)
+	mock.ExpectQuery(sanitizeQuery(statSubscriptionStatsQuery)).WillReturnRows(statsRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatSubscriptionCollector{log: log.NewNopLogger()}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatSubscriptionCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 1700000000.25},
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 1700000000.5},
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 12.5},
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 8192},
+		{labels: labelMap{"subname": "warehouse"}, metricType: dto.MetricType_COUNTER, value: 3},
+		{labels: labelMap{"subname": "warehouse"}, metricType: dto.MetricType_COUNTER, value: 1},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatSubscriptionCollectorPre15(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("14.0.0")}
+
+	columns := []string{"subname", "pid", "last_msg_send_time", "last_msg_receipt_time", "seconds_since_last_msg", "latest_end_lsn_lag"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("warehouse", 4242, 1700000000.25, 1700000000.5, 12.5, 0)
+	mock.ExpectQuery(sanitizeQuery(statSubscriptionQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatSubscriptionCollector{log: log.NewNopLogger()}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatSubscriptionCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 1700000000.25},
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 1700000000.5},
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 12.5},
+		{labels: labelMap{"subname": "warehouse", "pid": "4242"}, metricType: dto.MetricType_GAUGE, value: 0},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
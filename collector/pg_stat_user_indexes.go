@@ -0,0 +1,183 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statUserIndexesSubsystem = "stat_user_indexes"
+
+func init() {
+	registerCollector(statUserIndexesSubsystem, defaultDisabled, NewPGStatUserIndexesCollector)
+}
+
+var (
+	statUserIndexesIncludeSchemas = kingpin.Flag("collector.stat_user_indexes.include-schemas", "Comma-separated list of schemas to report in the stat_user_indexes collector. All schemas are reported when empty.").Default("").String()
+	statUserIndexesExcludeSchemas = kingpin.Flag("collector.stat_user_indexes.exclude-schemas", "Comma-separated list of schemas to leave out of the stat_user_indexes collector.").Default("").String()
+)
+
+type PGStatUserIndexesCollector struct {
+	log       log.Logger
+	schemas   schemaFilter
+	databases databaseFilter
+}
+
+func NewPGStatUserIndexesCollector(config collectorConfig) (Collector, error) {
+	return &PGStatUserIndexesCollector{
+		log:       config.logger,
+		schemas:   newSchemaFilter(*statUserIndexesIncludeSchemas, *statUserIndexesExcludeSchemas),
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	statUserIndexesIdxScan = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statUserIndexesSubsystem, "idx_scan_total"),
+		"Number of index scans initiated on this index",
+		[]string{"datname", "schemaname", "relname", "indexrelname"},
+		prometheus.Labels{},
+	)
+	statUserIndexesIdxTupRead = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statUserIndexesSubsystem, "idx_tup_read_total"),
+		"Number of index entries returned by scans on this index",
+		[]string{"datname", "schemaname", "relname", "indexrelname"},
+		prometheus.Labels{},
+	)
+	statUserIndexesIdxTupFetch = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statUserIndexesSubsystem, "idx_tup_fetch_total"),
+		"Number of live table rows fetched by simple index scans using this index",
+		[]string{"datname", "schemaname", "relname", "indexrelname"},
+		prometheus.Labels{},
+	)
+	statUserIndexesLastIdxScan = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statUserIndexesSubsystem, "last_idx_scan_time_seconds"),
+		"Time of the last scan on this index as a Unix epoch",
+		[]string{"datname", "schemaname", "relname", "indexrelname"},
+		prometheus.Labels{},
+	)
+
+	statUserIndexesQuery = `
+	SELECT
+		current_database() datname,
+		schemaname,
+		relname,
+		indexrelname,
+		idx_scan,
+		idx_tup_read,
+		idx_tup_fetch,
+		EXTRACT(EPOCH FROM last_idx_scan) AS last_idx_scan
+	FROM pg_stat_user_indexes
+	`
+
+	// last_idx_scan was added in PostgreSQL 16
+	statUserIndexesQueryPre16 = `
+	SELECT
+		current_database() datname,
+		schemaname,
+		relname,
+		indexrelname,
+		idx_scan,
+		idx_tup_read,
+		idx_tup_fetch,
+		NULL::double precision AS last_idx_scan
+	FROM pg_stat_user_indexes
+	`
+)
+
+func (c *PGStatUserIndexesCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	query := statUserIndexesQuery
+	if instance.version.LT(semver.MustParse("16.0.0")) {
+		query = statUserIndexesQueryPre16
+	}
+
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx,
+		query)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var datname, schemaname, relname, indexrelname sql.NullString
+		var idxScan, idxTupRead, idxTupFetch, lastIdxScan sql.NullFloat64
+
+		if err := rows.Scan(&datname, &schemaname, &relname, &indexrelname, &idxScan, &idxTupRead, &idxTupFetch, &lastIdxScan); err != nil {
+			return err
+		}
+		if !c.schemas.allowed(schemaname.String) {
+			continue
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		schemanameLabel := "unknown"
+		if schemaname.Valid {
+			schemanameLabel = schemaname.String
+		}
+		relnameLabel := "unknown"
+		if relname.Valid {
+			relnameLabel = relname.String
+		}
+		indexrelnameLabel := "unknown"
+		if indexrelname.Valid {
+			indexrelnameLabel = indexrelname.String
+		}
+		labels := []string{datnameLabel, schemanameLabel, relnameLabel, indexrelnameLabel}
+
+		for _, counter := range []struct {
+			desc  *prometheus.Desc
+			value sql.NullFloat64
+		}{
+			{statUserIndexesIdxScan, idxScan},
+			{statUserIndexesIdxTupRead, idxTupRead},
+			{statUserIndexesIdxTupFetch, idxTupFetch},
+		} {
+			ch <- prometheus.MustNewConstMetric(
+				counter.desc,
+				prometheus.CounterValue,
+				counter.value.Float64,
+				labels...,
+			)
+		}
+
+		// last_idx_scan is NULL for indexes that have never been scanned.
+		if lastIdxScan.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				statUserIndexesLastIdxScan,
+				prometheus.GaugeValue,
+				lastIdxScan.Float64,
+				labels...,
+			)
+		}
+	}
+	return rows.Err()
+}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -27,12 +28,23 @@ func init() {
 	registerCollector(userTableSubsystem, defaultEnabled, NewPGStatUserTablesCollector)
 }
 
+var (
+	statUserTablesIncludeSchemas = kingpin.Flag("collector.stat_user_tables.include-schemas", "Comma-separated list of schemas to report in the stat_user_tables collector. All schemas are reported when empty.").Default("").String()
+	statUserTablesExcludeSchemas = kingpin.Flag("collector.stat_user_tables.exclude-schemas", "Comma-separated list of schemas to leave out of the stat_user_tables collector.").Default("").String()
+)
+
 type PGStatUserTablesCollector struct {
-	log log.Logger
+	log       log.Logger
+	databases databaseFilter
+	schemas   schemaFilter
 }
 
 func NewPGStatUserTablesCollector(config collectorConfig) (Collector, error) {
-	return &PGStatUserTablesCollector{log: config.logger}, nil
+	return &PGStatUserTablesCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		schemas:   newSchemaFilter(*statUserTablesIncludeSchemas, *statUserTablesExcludeSchemas),
+	}, nil
 }
 
 var (
@@ -156,6 +168,12 @@ var (
 		[]string{"datname", "schemaname", "relname"},
 		prometheus.Labels{},
 	)
+	statUserTablesTupleChurn = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, userTableSubsystem, "tuple_churn_total"),
+		"Number of rows inserted, updated or deleted. The rate of this counter shows which tables drive WAL and autovacuum load",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
 
 	statUserTablesQuery = `SELECT
 		current_database() datname,
@@ -187,6 +205,14 @@ var (
 
 func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
 	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
 	rows, err := db.QueryContext(ctx,
 		statUserTablesQuery)
 
@@ -204,6 +230,9 @@ func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instan
 		if err := rows.Scan(&datname, &schemaname, &relname, &seqScan, &seqTupRead, &idxScan, &idxTupFetch, &nTupIns, &nTupUpd, &nTupDel, &nTupHotUpd, &nLiveTup, &nDeadTup, &nModSinceAnalyze, &lastVacuum, &lastAutovacuum, &lastAnalyze, &lastAutoanalyze, &vacuumCount, &autovacuumCount, &analyzeCount, &autoanalyzeCount, &totalSize); err != nil {
 			return err
 		}
+		if !c.schemas.allowed(schemaname.String) {
+			continue
+		}
 
 		datnameLabel := "unknown"
 		if datname.Valid {
@@ -341,7 +370,7 @@ func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instan
 
 		lastVacuumMetric := 0.0
 		if lastVacuum.Valid {
-			lastVacuumMetric = float64(lastVacuum.Time.Unix())
+			lastVacuumMetric = timeToEpochSeconds(lastVacuum.Time)
 		}
 		ch <- prometheus.MustNewConstMetric(
 			statUserTablesLastVacuum,
@@ -352,7 +381,7 @@ func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instan
 
 		lastAutovacuumMetric := 0.0
 		if lastAutovacuum.Valid {
-			lastAutovacuumMetric = float64(lastAutovacuum.Time.Unix())
+			lastAutovacuumMetric = timeToEpochSeconds(lastAutovacuum.Time)
 		}
 		ch <- prometheus.MustNewConstMetric(
 			statUserTablesLastAutovacuum,
@@ -363,7 +392,7 @@ func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instan
 
 		lastAnalyzeMetric := 0.0
 		if lastAnalyze.Valid {
-			lastAnalyzeMetric = float64(lastAnalyze.Time.Unix())
+			lastAnalyzeMetric = timeToEpochSeconds(lastAnalyze.Time)
 		}
 		ch <- prometheus.MustNewConstMetric(
 			statUserTablesLastAnalyze,
@@ -374,7 +403,7 @@ func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instan
 
 		lastAutoanalyzeMetric := 0.0
 		if lastAutoanalyze.Valid {
-			lastAutoanalyzeMetric = float64(lastAutoanalyze.Time.Unix())
+			lastAutoanalyzeMetric = timeToEpochSeconds(lastAutoanalyze.Time)
 		}
 		ch <- prometheus.MustNewConstMetric(
 			statUserTablesLastAutoanalyze,
@@ -437,6 +466,13 @@ func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instan
 			totalSizeMetric,
 			datnameLabel, schemanameLabel, relnameLabel,
 		)
+
+		ch <- prometheus.MustNewConstMetric(
+			statUserTablesTupleChurn,
+			prometheus.CounterValue,
+			nTupInsMetric+nTupUpdMetric+nTupDelMetric,
+			datnameLabel, schemanameLabel, relnameLabel,
+		)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -444,3 +480,29 @@ func (c *PGStatUserTablesCollector) Update(ctx context.Context, instance *instan
 	}
 	return nil
 }
+
+// schemaFilter limits per-table collectors to a set of schemas to keep their
+// cardinality under control. Unlike databaseFilter, it is applied to the
+// scanned rows rather than in the query.
+type schemaFilter struct {
+	include []string
+	exclude []string
+}
+
+// newSchemaFilter builds a schemaFilter from comma-separated lists of schemas
+// to include and exclude.
+func newSchemaFilter(include, exclude string) schemaFilter {
+	return schemaFilter{
+		include: splitList(include),
+		exclude: splitList(exclude),
+	}
+}
+
+// allowed reports whether tables in schema should be reported. An empty
+// include list allows every schema that is not excluded.
+func (f schemaFilter) allowed(schema string) bool {
+	if sliceContains(f.exclude, schema) {
+		return false
+	}
+	return len(f.include) == 0 || sliceContains(f.include, schema)
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const statWALSubsystem = "stat_wal"
+
+func init() {
+	registerCollector(statWALSubsystem, defaultEnabled, NewPGStatWALCollector)
+}
+
+type PGStatWALCollector struct {
+	log log.Logger
+}
+
+func NewPGStatWALCollector(config collectorConfig) (Collector, error) {
+	return &PGStatWALCollector{
+		log: config.logger,
+	}, nil
+}
+
+var (
+	statWALRecordsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "records_total"),
+		"Total number of WAL records generated",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALFPIDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "fpi_total"),
+		"Total number of WAL full page images generated",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "bytes_total"),
+		"Total amount of WAL generated in bytes",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALBuffersFullDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "buffers_full_total"),
+		"Number of times WAL data was written to disk because WAL buffers became full",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALWriteDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "write_total"),
+		"Number of times WAL buffers were written out to disk",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALSyncDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "sync_total"),
+		"Number of times WAL files were synced to disk",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALWriteTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "write_time_seconds_total"),
+		"Total amount of time spent writing WAL buffers to disk, in seconds. Requires track_wal_io_timing",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALSyncTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "sync_time_seconds_total"),
+		"Total amount of time spent syncing WAL files to disk, in seconds. Requires track_wal_io_timing",
+		[]string{},
+		prometheus.Labels{},
+	)
+	statWALStatsResetTimestampDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, statWALSubsystem, "stats_reset_timestamp_seconds"),
+		"Time at which these statistics were last reset, in seconds since the Unix epoch",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	statWALQuery = `SELECT
+		wal_records
+		,wal_fpi
+		,wal_bytes
+		,wal_buffers_full
+		,wal_write
+		,wal_sync
+		,wal_write_time
+		,wal_sync_time
+		,stats_reset
+	FROM pg_stat_wal;`
+
+	// PostgreSQL 18 moved the write and sync counters and timings to
+	// pg_stat_io, where they are reported for object 'wal'.
+	statWALQuery18 = `SELECT
+		w.wal_records
+		,w.wal_fpi
+		,w.wal_bytes
+		,w.wal_buffers_full
+		,io.writes AS wal_write
+		,io.fsyncs AS wal_sync
+		,io.write_time AS wal_write_time
+		,io.fsync_time AS wal_sync_time
+		,w.stats_reset
+	FROM pg_stat_wal w
+	CROSS JOIN (
+		SELECT
+			sum(writes) AS writes,
+			sum(fsyncs) AS fsyncs,
+			sum(write_time) AS write_time,
+			sum(fsync_time) AS fsync_time
+		FROM pg_stat_io
+		WHERE object = 'wal' AND context = 'normal'
+	) io;`
+)
+
+func (c PGStatWALCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_stat_wal was added in PostgreSQL 14
+	if instance.version.LT(semver.MustParse("14.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_stat_wal is not available before PostgreSQL 14")
+		return nil
+	}
+
+	query := statWALQuery
+	if instance.version.GE(semver.MustParse("18.0.0")) {
+		query = statWALQuery18
+	}
+
+	db := instance.getDB()
+	row := db.QueryRowContext(ctx,
+		query)
+
+	var records, fpi, bytes, buffersFull, write, sync, writeTime, syncTime sql.NullFloat64
+	var statsReset sql.NullTime
+
+	err := row.Scan(&records, &fpi, &bytes, &buffersFull, &write, &sync, &writeTime, &syncTime, &statsReset)
+	if err != nil {
+		return err
+	}
+
+	for _, counter := range []struct {
+		desc  *prometheus.Desc
+		value sql.NullFloat64
+		scale float64
+	}{
+		{statWALRecordsDesc, records, 1},
+		{statWALFPIDesc, fpi, 1},
+		{statWALBytesDesc, bytes, 1},
+		{statWALBuffersFullDesc, buffersFull, 1},
+		{statWALWriteDesc, write, 1},
+		{statWALSyncDesc, sync, 1},
+		// The timings are reported in milliseconds
+		{statWALWriteTimeDesc, writeTime, 1000.0},
+		{statWALSyncTimeDesc, syncTime, 1000.0},
+	} {
+		if !counter.value.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			counter.desc,
+			prometheus.CounterValue,
+			counter.value.Float64/counter.scale,
+		)
+	}
+
+	statsResetMetric := 0.0
+	if statsReset.Valid {
+		statsResetMetric = timeToEpochSeconds(statsReset.Time)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		statWALStatsResetTimestampDesc,
+		prometheus.GaugeValue,
+		statsResetMetric,
+	)
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var statWALColumns = []string{
+	"wal_records",
+	"wal_fpi",
+	"wal_bytes",
+	"wal_buffers_full",
+	"wal_write",
+	"wal_sync",
+	"wal_write_time",
+	"wal_sync_time",
+	"stats_reset",
+}
+
+func TestPGStatWALCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("14.0.0")}
+
+	srT, err := time.Parse("2006-01-02 15:04:05.00000-07", "2023-05-25 17:10:42.81132-07")
+	if err != nil {
+		t.Fatalf("Error parsing time: %s", err)
+	}
+
+	rows := sqlmock.NewRows(statWALColumns).
+		AddRow(2000, 150, 1048576, 3, 400, 380, 1250, 3500, srT)
+	mock.ExpectQuery(sanitizeQuery(statWALQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatWALCollector{
+			log: log.With(log.NewNopLogger(), "collector", "stat_wal"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatWALCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 2000},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 150},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 1048576},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 3},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 400},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 380},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 1.25},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 3.5},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 1685059842.81132},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGStatWALCollectorPG18(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("18.0.0")}
+
+	rows := sqlmock.NewRows(statWALColumns).
+		AddRow(2000, 150, 1048576, 3, 400, nil, nil, nil, nil)
+	mock.ExpectQuery(sanitizeQuery(statWALQuery18)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGStatWALCollector{
+			log: log.With(log.NewNopLogger(), "collector", "stat_wal"),
+		}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGStatWALCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 2000},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 150},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 1048576},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 3},
+		{labels: labelMap{}, metricType: dto.MetricType_COUNTER, value: 400},
+		{labels: labelMap{}, metricType: dto.MetricType_GAUGE, value: 0},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
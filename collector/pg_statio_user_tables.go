@@ -17,6 +17,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -27,12 +28,23 @@ func init() {
 	registerCollector(statioUserTableSubsystem, defaultEnabled, NewPGStatIOUserTablesCollector)
 }
 
+var (
+	statioUserTablesIncludeSchemas = kingpin.Flag("collector.statio_user_tables.include-schemas", "Comma-separated list of schemas to report in the statio_user_tables collector. All schemas are reported when empty.").Default("").String()
+	statioUserTablesExcludeSchemas = kingpin.Flag("collector.statio_user_tables.exclude-schemas", "Comma-separated list of schemas to leave out of the statio_user_tables collector.").Default("").String()
+)
+
 type PGStatIOUserTablesCollector struct {
-	log log.Logger
+	log       log.Logger
+	databases databaseFilter
+	schemas   schemaFilter
 }
 
 func NewPGStatIOUserTablesCollector(config collectorConfig) (Collector, error) {
-	return &PGStatIOUserTablesCollector{log: config.logger}, nil
+	return &PGStatIOUserTablesCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		schemas:   newSchemaFilter(*statioUserTablesIncludeSchemas, *statioUserTablesExcludeSchemas),
+	}, nil
 }
 
 var (
@@ -100,8 +112,16 @@ var (
 	FROM pg_statio_user_tables`
 )
 
-func (PGStatIOUserTablesCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+func (c PGStatIOUserTablesCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
 	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
 	rows, err := db.QueryContext(ctx,
 		statioUserTablesQuery)
 
@@ -117,6 +137,10 @@ func (PGStatIOUserTablesCollector) Update(ctx context.Context, instance *instanc
 		if err := rows.Scan(&datname, &schemaname, &relname, &heapBlksRead, &heapBlksHit, &idxBlksRead, &idxBlksHit, &toastBlksRead, &toastBlksHit, &tidxBlksRead, &tidxBlksHit); err != nil {
 			return err
 		}
+		if !c.schemas.allowed(schemaname.String) {
+			continue
+		}
+
 		datnameLabel := "unknown"
 		if datname.Valid {
 			datnameLabel = datname.String
@@ -0,0 +1,142 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const subscriptionSubsystem = "subscription"
+
+func init() {
+	registerCollector(subscriptionSubsystem, defaultDisabled, NewPGSubscriptionCollector)
+}
+
+// PGSubscriptionCollector reports whether each logical replication
+// subscription is enabled and whether its apply worker is running. An
+// enabled subscription without a worker is not replicating.
+type PGSubscriptionCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGSubscriptionCollector(config collectorConfig) (Collector, error) {
+	return &PGSubscriptionCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	subscriptionEnabled = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subscriptionSubsystem, "enabled"),
+		"Whether the subscription is enabled (1 = enabled, 0 = disabled)",
+		[]string{"datname", "subname"},
+		prometheus.Labels{},
+	)
+	subscriptionWorkerRunning = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subscriptionSubsystem, "worker_running"),
+		"Whether an apply worker is running for the subscription (1 = running, 0 = not running)",
+		[]string{"datname", "subname"},
+		prometheus.Labels{},
+	)
+
+	// Only the columns readable by non-superusers are selected: subconninfo
+	// is revoked from public.
+	subscriptionQuery = `
+	SELECT
+		d.datname,
+		s.subname,
+		s.subenabled,
+		EXISTS (
+			SELECT 1 FROM pg_catalog.pg_stat_subscription ss
+			WHERE ss.subid = s.oid AND ss.relid IS NULL AND ss.pid IS NOT NULL
+		) AS worker_running
+	FROM pg_catalog.pg_subscription s
+	LEFT JOIN pg_catalog.pg_database d ON d.oid = s.subdbid
+	`
+)
+
+// pqInsufficientPrivilege is the SQLSTATE of permission denied errors.
+const pqInsufficientPrivilege = "42501"
+
+func (c *PGSubscriptionCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// Logical replication subscriptions were added in PostgreSQL 10
+	if instance.version.LT(semver.MustParse("10.0.0")) {
+		level.Debug(c.log).Log("msg", "subscription collector is not available on PostgreSQL < 10, skipping")
+		return nil
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx, subscriptionQuery)
+	if err != nil {
+		// Managed platforms may not grant access to pg_subscription.
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pqInsufficientPrivilege {
+			level.Debug(c.log).Log("msg", "Not allowed to read pg_subscription, skipping", "err", err)
+			return nil
+		}
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, subname sql.NullString
+		var enabled, workerRunning sql.NullBool
+		if err := rows.Scan(&datname, &subname, &enabled, &workerRunning); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+		if !subname.Valid {
+			continue
+		}
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+
+		enabledMetric := 0.0
+		if enabled.Bool {
+			enabledMetric = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(
+			subscriptionEnabled,
+			prometheus.GaugeValue,
+			enabledMetric,
+			datnameLabel, subname.String,
+		)
+
+		workerRunningMetric := 0.0
+		if workerRunning.Bool {
+			workerRunningMetric = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(
+			subscriptionWorkerRunning,
+			prometheus.GaugeValue,
+			workerRunningMetric,
+			datnameLabel, subname.String,
+		)
+	}
+	return rows.Err()
+}
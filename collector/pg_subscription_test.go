@@ -0,0 +1,102 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGSubscriptionCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	rows := sqlmock.NewRows([]string{"datname", "subname", "subenabled", "worker_running"}).
+		AddRow("warehouse", "orders_sub", true, true).
+		AddRow("warehouse", "broken_sub", true, false).
+		AddRow(nil, "paused_sub", false, false)
+	mock.ExpectQuery(sanitizeQuery(subscriptionQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGSubscriptionCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGSubscriptionCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "warehouse", "subname": "orders_sub"}, metricType: dto.MetricType_GAUGE, value: 1},
+		{labels: labelMap{"datname": "warehouse", "subname": "orders_sub"}, metricType: dto.MetricType_GAUGE, value: 1},
+		{labels: labelMap{"datname": "warehouse", "subname": "broken_sub"}, metricType: dto.MetricType_GAUGE, value: 1},
+		{labels: labelMap{"datname": "warehouse", "subname": "broken_sub"}, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: labelMap{"datname": "unknown", "subname": "paused_sub"}, metricType: dto.MetricType_GAUGE, value: 0},
+		{labels: labelMap{"datname": "unknown", "subname": "paused_sub"}, metricType: dto.MetricType_GAUGE, value: 0},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGSubscriptionCollectorPermissionDenied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("16.0.0")}
+
+	mock.ExpectQuery(sanitizeQuery(subscriptionQuery)).
+		WillReturnError(&pq.Error{Code: pqInsufficientPrivilege, Message: "permission denied for table pg_subscription"})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGSubscriptionCollector{log: log.NewNopLogger()}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGSubscriptionCollector.Update: %s", err)
+		}
+	}()
+
+	convey.Convey("No metrics without access to pg_subscription", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
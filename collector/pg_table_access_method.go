@@ -0,0 +1,105 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tableAccessMethodSubsystem = "table_access_method"
+
+func init() {
+	registerCollector(tableAccessMethodSubsystem, defaultDisabled, NewPGTableAccessMethodCollector)
+}
+
+type PGTableAccessMethodCollector struct {
+	log log.Logger
+}
+
+func NewPGTableAccessMethodCollector(config collectorConfig) (Collector, error) {
+	return &PGTableAccessMethodCollector{log: config.logger}, nil
+}
+
+var (
+	tablesByAccessMethod = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "tables", "by_access_method"),
+		"Number of tables and materialized views using the table access method",
+		[]string{"amname"},
+		prometheus.Labels{},
+	)
+
+	tableAccessMethodQuery = `
+	SELECT
+		am.amname,
+		count(*) AS tables
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_am am ON am.oid = c.relam
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind IN ('r', 'm')
+	AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+	AND n.nspname NOT LIKE 'pg_toast%'
+	GROUP BY am.amname
+	`
+)
+
+func (c *PGTableAccessMethodCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// Pluggable table access methods were added in PostgreSQL 12
+	if instance.version.LT(semver.MustParse("12.0.0")) {
+		level.Debug(c.log).Log("msg", "Table access methods are not available before PostgreSQL 12")
+		return nil
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		tableAccessMethodQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var amname sql.NullString
+		var tables sql.NullInt64
+
+		if err := rows.Scan(&amname, &tables); err != nil {
+			return err
+		}
+
+		if !amname.Valid {
+			continue
+		}
+
+		tablesMetric := 0.0
+		if tables.Valid {
+			tablesMetric = float64(tables.Int64)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tablesByAccessMethod,
+			prometheus.GaugeValue,
+			tablesMetric,
+			amname.String,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
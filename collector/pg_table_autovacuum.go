@@ -0,0 +1,118 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tableAutovacuumSubsystem = "table_autovacuum"
+
+func init() {
+	registerCollector(tableAutovacuumSubsystem, defaultEnabled, NewPGTableAutovacuumCollector)
+}
+
+type PGTableAutovacuumCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGTableAutovacuumCollector(config collectorConfig) (Collector, error) {
+	return &PGTableAutovacuumCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	tableAutovacuumDisabled = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "table", "autovacuum_disabled"),
+		"Set to 1 for each table which has autovacuum disabled through its autovacuum_enabled storage parameter",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
+
+	// reloptions keeps the value as it was written in the ALTER TABLE, so
+	// it is parsed as a boolean in Go rather than compared in SQL.
+	tableAutovacuumQuery = `
+	SELECT
+		current_database() AS datname,
+		n.nspname AS schemaname,
+		c.relname,
+		substring(opt FROM '^autovacuum_enabled=(.*)$') AS autovacuum_enabled
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL unnest(c.reloptions) AS opt
+	WHERE c.relkind IN ('r', 'm')
+	AND opt LIKE 'autovacuum_enabled=%'
+	`
+)
+
+func (c *PGTableAutovacuumCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx,
+		tableAutovacuumQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, schemaname, relname, enabled sql.NullString
+
+		if err := rows.Scan(&datname, &schemaname, &relname, &enabled); err != nil {
+			return err
+		}
+		if !datname.Valid || !schemaname.Valid || !relname.Valid || !enabled.Valid {
+			continue
+		}
+		if !isPostgresFalse(enabled.String) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tableAutovacuumDisabled,
+			prometheus.GaugeValue,
+			1,
+			datname.String, schemaname.String, relname.String,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// isPostgresFalse reports whether s is one of the spellings PostgreSQL
+// accepts for a false boolean: off, 0, or any prefix of false or no.
+func isPostgresFalse(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return false
+	}
+	return s == "off" || s == "0" || strings.HasPrefix("false", s) || strings.HasPrefix("no", s)
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tableToastSubsystem = "table_toast"
+
+func init() {
+	registerCollector(tableToastSubsystem, defaultDisabled, NewPGTableToastCollector)
+}
+
+type PGTableToastCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGTableToastCollector(config collectorConfig) (Collector, error) {
+	return &PGTableToastCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	tableToastSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "table", "toast_size_bytes"),
+		"Disk space used by the TOAST table of the table, excluding its index",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
+	tableMainSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "table", "main_size_bytes"),
+		"Disk space used by the main fork of the table",
+		[]string{"datname", "schemaname", "relname"},
+		prometheus.Labels{},
+	)
+
+	// Tables without a TOAST table have no out-of-line storage to
+	// compare against and are left out.
+	tableToastQuery = `
+	SELECT
+		current_database() AS datname,
+		n.nspname AS schemaname,
+		c.relname,
+		pg_relation_size(c.oid, 'main') AS main_size_bytes,
+		pg_relation_size(c.reltoastrelid) AS toast_size_bytes
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind IN ('r', 'm')
+	AND c.reltoastrelid <> 0
+	AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+	AND n.nspname NOT LIKE 'pg_toast%'
+	`
+)
+
+func (c *PGTableToastCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	allowed, err := c.databases.allowedCurrent(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx,
+		tableToastQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, schemaname, relname sql.NullString
+		var mainSize, toastSize sql.NullFloat64
+
+		if err := rows.Scan(&datname, &schemaname, &relname, &mainSize, &toastSize); err != nil {
+			return err
+		}
+		if !datname.Valid || !schemaname.Valid || !relname.Valid {
+			continue
+		}
+		labels := []string{datname.String, schemaname.String, relname.String}
+
+		mainSizeMetric := 0.0
+		if mainSize.Valid {
+			mainSizeMetric = mainSize.Float64
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tableMainSizeBytes,
+			prometheus.GaugeValue,
+			mainSizeMetric,
+			labels...,
+		)
+
+		toastSizeMetric := 0.0
+		if toastSize.Valid {
+			toastSizeMetric = toastSize.Float64
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tableToastSizeBytes,
+			prometheus.GaugeValue,
+			toastSizeMetric,
+			labels...,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tablespaceSubsystem = "tablespace"
+
+func init() {
+	registerCollector(tablespaceSubsystem, defaultDisabled, NewPGTablespaceCollector)
+}
+
+type PGTablespaceCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGTablespaceCollector(config collectorConfig) (Collector, error) {
+	return &PGTablespaceCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	tablespaceSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tablespaceSubsystem, "size_bytes"),
+		"Disk space used by the tablespace",
+		[]string{"spcname"},
+		prometheus.Labels{},
+	)
+	tablespaceRelationBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tablespaceSubsystem, "relation_bytes"),
+		"Disk space used by the relations of the current database stored in the tablespace",
+		[]string{"datname", "spcname"},
+		prometheus.Labels{},
+	)
+
+	// Relations with reltablespace 0 live in the default tablespace of the
+	// database.
+	tablespaceRelationBytesQuery = `
+	SELECT
+		current_database() AS datname,
+		t.spcname,
+		sum(pg_relation_size(c.oid)) AS size_bytes
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_tablespace t ON t.oid = COALESCE(
+		NULLIF(c.reltablespace, 0),
+		(SELECT dattablespace FROM pg_catalog.pg_database WHERE datname = current_database())
+	)
+	WHERE c.relkind IN ('r', 'i', 'm', 't')
+	GROUP BY t.spcname
+	`
+
+	tablespaceQuery     = "SELECT spcname FROM pg_catalog.pg_tablespace"
+	tablespaceSizeQuery = "SELECT pg_tablespace_size($1)"
+)
+
+func (c *PGTablespaceCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		tablespaceRelationBytesQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, spcname sql.NullString
+		var sizeBytes sql.NullFloat64
+
+		if err := rows.Scan(&datname, &spcname, &sizeBytes); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		if !spcname.Valid {
+			continue
+		}
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+
+		sizeBytesMetric := 0.0
+		if sizeBytes.Valid {
+			sizeBytesMetric = sizeBytes.Float64
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tablespaceRelationBytes,
+			prometheus.GaugeValue,
+			sizeBytesMetric,
+			datnameLabel, spcname.String,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return c.updateSizes(ctx, db, ch)
+}
+
+// updateSizes exposes the size of every tablespace. pg_tablespace_size()
+// needs CREATE on the tablespace or pg_read_all_stats, so tablespaces the
+// connecting role cannot read are skipped instead of failing the scrape.
+func (c *PGTablespaceCollector) updateSizes(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, tablespaceQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tablespaces []string
+	for rows.Next() {
+		var spcname sql.NullString
+		if err := rows.Scan(&spcname); err != nil {
+			return err
+		}
+		if spcname.Valid {
+			tablespaces = append(tablespaces, spcname.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, spcname := range tablespaces {
+		var size sql.NullFloat64
+		if err := db.QueryRowContext(ctx, tablespaceSizeQuery, spcname).Scan(&size); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == pqInsufficientPrivilege {
+				level.Debug(c.log).Log("msg", "Not allowed to read tablespace size, skipping", "spcname", spcname, "err", err)
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tablespaceSizeBytes,
+			prometheus.GaugeValue,
+			size.Float64,
+			spcname,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGTablespaceCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	rows := sqlmock.NewRows([]string{"datname", "spcname", "size_bytes"}).
+		AddRow("postgres", "pg_default", 8388608).
+		AddRow("postgres", "pg_global", 557056).
+		AddRow("postgres", nil, 8192)
+	mock.ExpectQuery(sanitizeQuery(tablespaceRelationBytesQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(tablespaceQuery)).WillReturnRows(sqlmock.NewRows([]string{"spcname"}).
+		AddRow("pg_default"))
+	mock.ExpectQuery(sanitizeQuery(tablespaceSizeQuery)).WithArgs("pg_default").WillReturnRows(sqlmock.NewRows([]string{"pg_tablespace_size"}).
+		AddRow(41943040))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGTablespaceCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGTablespaceCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"datname": "postgres", "spcname": "pg_default"}, metricType: dto.MetricType_GAUGE, value: 8388608},
+		{labels: labelMap{"datname": "postgres", "spcname": "pg_global"}, metricType: dto.MetricType_GAUGE, value: 557056},
+		{labels: labelMap{"spcname": "pg_default"}, metricType: dto.MetricType_GAUGE, value: 41943040},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGTablespaceCollectorSizePermissionDenied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	mock.ExpectQuery(sanitizeQuery(tablespaceRelationBytesQuery)).WillReturnRows(sqlmock.NewRows([]string{"datname", "spcname", "size_bytes"}))
+	mock.ExpectQuery(sanitizeQuery(tablespaceQuery)).WillReturnRows(sqlmock.NewRows([]string{"spcname"}).
+		AddRow("archive").
+		AddRow("fast_ssd"))
+	mock.ExpectQuery(sanitizeQuery(tablespaceSizeQuery)).WithArgs("archive").
+		WillReturnError(&pq.Error{Code: pqInsufficientPrivilege, Message: "permission denied for tablespace archive"})
+	mock.ExpectQuery(sanitizeQuery(tablespaceSizeQuery)).WithArgs("fast_ssd").WillReturnRows(sqlmock.NewRows([]string{"pg_tablespace_size"}).
+		AddRow(1073741824))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGTablespaceCollector{log: log.NewNopLogger()}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGTablespaceCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"spcname": "fast_ssd"}, metricType: dto.MetricType_GAUGE, value: 1073741824},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPGTablespaceCollectorSizeError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	mock.ExpectQuery(sanitizeQuery(tablespaceRelationBytesQuery)).WillReturnRows(sqlmock.NewRows([]string{"datname", "spcname", "size_bytes"}))
+	mock.ExpectQuery(sanitizeQuery(tablespaceQuery)).WillReturnRows(sqlmock.NewRows([]string{"spcname"}).
+		AddRow("archive").
+		AddRow("fast_ssd"))
+	mock.ExpectQuery(sanitizeQuery(tablespaceSizeQuery)).WithArgs("archive").
+		WillReturnError(context.DeadlineExceeded)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGTablespaceCollector{log: log.NewNopLogger()}
+
+		err := c.Update(context.Background(), inst, ch)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("PGTablespaceCollector.Update() = %v, want %v", err, context.DeadlineExceeded)
+		}
+	}()
+
+	convey.Convey("No size is reported after a failed size query", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tempTablespaceSubsystem = "temp_tablespace"
+
+func init() {
+	registerCollector(tempTablespaceSubsystem, defaultDisabled, NewPGTempTablespaceCollector)
+}
+
+type PGTempTablespaceCollector struct {
+	log log.Logger
+}
+
+func NewPGTempTablespaceCollector(config collectorConfig) (Collector, error) {
+	return &PGTempTablespaceCollector{log: config.logger}, nil
+}
+
+var (
+	tempBytesByTablespace = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "temp", "bytes_by_tablespace"),
+		"Total size of the temporary files currently in the tablespace",
+		[]string{"spcname"},
+		prometheus.Labels{},
+	)
+
+	// pg_global never holds temporary files.
+	tempTablespaceQuery = `
+	SELECT
+		t.spcname,
+		COALESCE(sum(f.size), 0) AS size_bytes
+	FROM pg_catalog.pg_tablespace t
+	LEFT JOIN LATERAL pg_ls_tmpdir(t.oid) f ON true
+	WHERE t.spcname <> 'pg_global'
+	GROUP BY t.spcname
+	`
+)
+
+func (c *PGTempTablespaceCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_ls_tmpdir with a tablespace argument was added in PostgreSQL 12
+	if instance.version.LT(semver.MustParse("12.0.0")) {
+		level.Debug(c.log).Log("msg", "pg_ls_tmpdir is not available before PostgreSQL 12")
+		return nil
+	}
+
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		tempTablespaceQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var spcname sql.NullString
+		var sizeBytes sql.NullFloat64
+
+		if err := rows.Scan(&spcname, &sizeBytes); err != nil {
+			return err
+		}
+
+		if !spcname.Valid {
+			continue
+		}
+
+		sizeBytesMetric := 0.0
+		if sizeBytes.Valid {
+			sizeBytesMetric = sizeBytes.Float64
+		}
+		ch <- prometheus.MustNewConstMetric(
+			tempBytesByTablespace,
+			prometheus.GaugeValue,
+			sizeBytesMetric,
+			spcname.String,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tempUsageSubsystem = "temp_usage"
+
+func init() {
+	// Requires the pg_stat_statements extension and creates one series per
+	// reported statement, so it is disabled by default and capped by
+	// --collector.temp_usage.limit.
+	registerCollector(tempUsageSubsystem, defaultDisabled, NewPGTempUsageCollector)
+}
+
+var tempUsageLimit = kingpin.Flag("collector.temp_usage.limit", "Maximum number of statements to report, largest temporary file usage first.").Default("10").Int()
+
+// PGTempUsageCollector attributes the temporary file usage reported per
+// database in pg_stat_database to the statements in pg_stat_statements that
+// wrote the most temporary blocks. The database totals themselves are
+// exported by the stat_database collector as pg_stat_database_temp_bytes.
+type PGTempUsageCollector struct {
+	log       log.Logger
+	databases databaseFilter
+	limit     int
+}
+
+func NewPGTempUsageCollector(config collectorConfig) (Collector, error) {
+	return &PGTempUsageCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+		limit:     *tempUsageLimit,
+	}, nil
+}
+
+var (
+	tempUsageStatementBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tempUsageSubsystem, "statement_bytes_total"),
+		"Total amount of data written to temporary files by the statement, in bytes",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+	tempUsageStatementRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tempUsageSubsystem, "statement_ratio"),
+		"Temporary bytes written by the statement divided by the temporary bytes written in its database. "+
+			"This is approximate as pg_stat_statements and pg_stat_database are reset independently",
+		[]string{"user", "datname", "queryid"},
+		prometheus.Labels{},
+	)
+
+	tempUsageStatementQuery = `
+	SELECT
+		pg_get_userbyid(s.userid) AS user,
+		d.datname,
+		s.queryid,
+		sum(s.temp_blks_written) * current_setting('block_size')::bigint AS temp_bytes,
+		sd.temp_bytes AS database_temp_bytes
+	FROM pg_stat_statements s
+	JOIN pg_database d ON d.oid = s.dbid
+	JOIN pg_stat_database sd ON sd.datid = s.dbid
+	WHERE s.temp_blks_written > 0%s
+	GROUP BY s.userid, d.datname, s.queryid, sd.temp_bytes
+	ORDER BY temp_bytes DESC
+	LIMIT $1
+	`
+)
+
+func (c *PGTempUsageCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	databases, args := c.databases.and("d.datname", []any{c.limit})
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(tempUsageStatementQuery, databases), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user, datname, queryid sql.NullString
+		var tempBytes, databaseTempBytes sql.NullFloat64
+		if err := rows.Scan(&user, &datname, &queryid, &tempBytes, &databaseTempBytes); err != nil {
+			return err
+		}
+
+		userLabel := "unknown"
+		if user.Valid {
+			userLabel = user.String
+		}
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		queryidLabel := "unknown"
+		if queryid.Valid {
+			queryidLabel = queryid.String
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			tempUsageStatementBytes,
+			prometheus.CounterValue,
+			tempBytes.Float64,
+			userLabel, datnameLabel, queryidLabel,
+		)
+
+		if databaseTempBytes.Valid && databaseTempBytes.Float64 > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				tempUsageStatementRatio,
+				prometheus.GaugeValue,
+				tempBytes.Float64/databaseTempBytes.Float64,
+				userLabel, datnameLabel, queryidLabel,
+			)
+		}
+	}
+	return rows.Err()
+}
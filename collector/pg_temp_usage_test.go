@@ -0,0 +1,69 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPGTempUsageCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db}
+
+	columns := []string{"user", "datname", "queryid", "temp_bytes", "database_temp_bytes"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "orders", 1500, 6144, 8192).
+		AddRow("app", "orders", 1600, 2048, 8192).
+		AddRow("report", "warehouse", 1700, 1024, nil)
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(tempUsageStatementQuery, ""))).WithArgs(10).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGTempUsageCollector{limit: 10}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGTempUsageCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"user": "app", "datname": "orders", "queryid": "1500"}, metricType: dto.MetricType_COUNTER, value: 6144},
+		{labels: labelMap{"user": "app", "datname": "orders", "queryid": "1500"}, metricType: dto.MetricType_GAUGE, value: 0.75},
+		{labels: labelMap{"user": "app", "datname": "orders", "queryid": "1600"}, metricType: dto.MetricType_COUNTER, value: 2048},
+		{labels: labelMap{"user": "app", "datname": "orders", "queryid": "1600"}, metricType: dto.MetricType_GAUGE, value: 0.25},
+		{labels: labelMap{"user": "report", "datname": "warehouse", "queryid": "1700"}, metricType: dto.MetricType_COUNTER, value: 1024},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
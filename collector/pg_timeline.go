@@ -0,0 +1,114 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const timelineSubsystem = "timeline"
+
+func init() {
+	registerCollector(timelineSubsystem, defaultDisabled, NewPGTimelineCollector)
+}
+
+// PGTimelineCollector exposes the server timeline alongside the timeline of
+// the replication slot streamed from, so that a standby still following a
+// stale timeline after a failover can be detected.
+type PGTimelineCollector struct {
+	log log.Logger
+}
+
+func NewPGTimelineCollector(config collectorConfig) (Collector, error) {
+	return &PGTimelineCollector{log: config.logger}, nil
+}
+
+var (
+	timelineCurrent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "current_timeline"),
+		"Timeline ID of the last checkpoint as recorded in the control file",
+		[]string{},
+		prometheus.Labels{},
+	)
+	timelineReplicationSlot = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, replicationSlotSubsystem, "timeline"),
+		"Timeline ID of the WAL last received through this upstream replication slot",
+		[]string{"slot_name"},
+		prometheus.Labels{},
+	)
+
+	timelineCurrentQuery = `SELECT timeline_id FROM pg_control_checkpoint()`
+
+	// pg_replication_slots does not record a timeline, so slot timelines are
+	// only available on a standby, where the WAL receiver reports the
+	// timeline it is streaming through its upstream slot.
+	timelineReplicationSlotQuery = `
+	SELECT
+		slot_name,
+		received_tli
+	FROM pg_stat_wal_receiver
+	WHERE COALESCE(slot_name, '') <> ''
+	`
+)
+
+func (c *PGTimelineCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	// pg_control_checkpoint was added in PostgreSQL 9.6
+	if instance.version.LT(semver.MustParse("9.6.0")) {
+		level.Debug(c.log).Log("msg", "pg_control_checkpoint is not available before PostgreSQL 9.6")
+		return nil
+	}
+
+	db := instance.getDB()
+	var timelineID sql.NullInt64
+	if err := db.QueryRowContext(ctx, timelineCurrentQuery).Scan(&timelineID); err != nil {
+		return err
+	}
+	if timelineID.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			timelineCurrent,
+			prometheus.GaugeValue,
+			float64(timelineID.Int64),
+		)
+	}
+
+	rows, err := db.QueryContext(ctx, timelineReplicationSlotQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slotName sql.NullString
+		var receivedTLI sql.NullInt64
+		if err := rows.Scan(&slotName, &receivedTLI); err != nil {
+			return err
+		}
+		if !slotName.Valid || !receivedTLI.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			timelineReplicationSlot,
+			prometheus.GaugeValue,
+			float64(receivedTLI.Int64),
+			slotName.String,
+		)
+	}
+	return rows.Err()
+}
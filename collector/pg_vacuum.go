@@ -0,0 +1,109 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const vacuumSubsystem = "vacuum"
+
+func init() {
+	registerCollector(vacuumSubsystem, defaultDisabled, NewPGVacuumCollector)
+}
+
+type PGVacuumCollector struct {
+	log       log.Logger
+	databases databaseFilter
+}
+
+func NewPGVacuumCollector(config collectorConfig) (Collector, error) {
+	return &PGVacuumCollector{
+		log:       config.logger,
+		databases: newDatabaseFilter(config.includeDatabases, config.excludeDatabases),
+	}, nil
+}
+
+var (
+	vacuumElapsedSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, vacuumSubsystem, "elapsed_seconds"),
+		"Time since the running vacuum started, in seconds",
+		[]string{"datname", "relname", "pid", "type"},
+		prometheus.Labels{},
+	)
+
+	vacuumElapsedQuery = `
+	SELECT
+		p.datname,
+		p.relid::regclass::text AS relname,
+		p.pid::text AS pid,
+		CASE WHEN a.query LIKE 'autovacuum:%' THEN 'autovacuum' ELSE 'manual' END AS type,
+		EXTRACT(EPOCH FROM (now() - a.query_start)) AS elapsed_seconds
+	FROM pg_catalog.pg_stat_progress_vacuum p
+	JOIN pg_catalog.pg_stat_activity a ON a.pid = p.pid
+	`
+)
+
+func (c *PGVacuumCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	db := instance.getDB()
+	rows, err := db.QueryContext(ctx,
+		vacuumElapsedQuery)
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var datname, relname, pid, vacuumType sql.NullString
+		var elapsedSeconds sql.NullFloat64
+
+		if err := rows.Scan(&datname, &relname, &pid, &vacuumType, &elapsedSeconds); err != nil {
+			return err
+		}
+		if !c.databases.allowed(datname.String) {
+			continue
+		}
+
+		if !pid.Valid || !elapsedSeconds.Valid {
+			level.Debug(c.log).Log("msg", "Skipping vacuum without pid or query_start")
+			continue
+		}
+
+		datnameLabel := "unknown"
+		if datname.Valid {
+			datnameLabel = datname.String
+		}
+		relnameLabel := "unknown"
+		if relname.Valid {
+			relnameLabel = relname.String
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			vacuumElapsedSeconds,
+			prometheus.GaugeValue,
+			elapsedSeconds.Float64,
+			datnameLabel, relnameLabel, pid.String, vacuumType.String,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
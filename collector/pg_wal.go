@@ -15,7 +15,9 @@ package collector
 
 import (
 	"context"
+	"database/sql"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -51,6 +53,25 @@ var (
 		"Total size of WAL segments",
 		[]string{}, nil,
 	)
+	pgWALBytesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(
+			namespace,
+			walSubsystem,
+			"bytes_total",
+		),
+		"WAL position in bytes, written on primary or replayed on replica. The rate of this counter is the WAL generation rate",
+		[]string{}, nil,
+	)
+
+	pgWALArchiveReadyFiles = prometheus.NewDesc(
+		prometheus.BuildFQName(
+			namespace,
+			walSubsystem,
+			"archive_ready_files",
+		),
+		"Number of WAL files waiting to be archived",
+		[]string{}, nil,
+	)
 
 	pgWALQuery = `
 		SELECT
@@ -58,6 +79,17 @@ var (
 			SUM(size) AS size
 		FROM pg_ls_waldir()
 		WHERE name ~ '^[0-9A-F]{24}$'`
+
+	pgWALBytesQuery = `
+		SELECT CASE
+			WHEN pg_is_in_recovery() THEN pg_wal_lsn_diff(pg_last_wal_replay_lsn(), '0/0')
+			ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), '0/0')
+		END AS bytes`
+
+	pgWALArchiveReadyQuery = `
+		SELECT COUNT(*) AS ready
+		FROM pg_ls_archive_statusdir()
+		WHERE name LIKE '%.ready'`
 )
 
 func (c PGWALCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
@@ -80,5 +112,37 @@ func (c PGWALCollector) Update(ctx context.Context, instance *instance, ch chan<
 		pgWALSize,
 		prometheus.GaugeValue, float64(size),
 	)
+
+	var bytes sql.NullFloat64
+	err = db.QueryRowContext(ctx,
+		pgWALBytesQuery,
+	).Scan(&bytes)
+	if err != nil {
+		return err
+	}
+	// The replay position is NULL on a replica which has not replayed
+	// any WAL since startup.
+	if bytes.Valid {
+		ch <- prometheus.MustNewConstMetric(
+			pgWALBytesTotal,
+			prometheus.CounterValue, bytes.Float64,
+		)
+	}
+
+	// pg_ls_archive_statusdir was added in PostgreSQL 12
+	if instance.version.LT(semver.MustParse("12.0.0")) {
+		return nil
+	}
+	var ready uint64
+	err = db.QueryRowContext(ctx,
+		pgWALArchiveReadyQuery,
+	).Scan(&ready)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(
+		pgWALArchiveReadyFiles,
+		prometheus.GaugeValue, float64(ready),
+	)
 	return nil
 }
@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
@@ -35,6 +36,7 @@ func TestPgWALCollector(t *testing.T) {
 	rows := sqlmock.NewRows(columns).
 		AddRow(47, 788529152)
 	mock.ExpectQuery(sanitizeQuery(pgWALQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(pgWALBytesQuery)).WillReturnRows(sqlmock.NewRows([]string{"bytes"}).AddRow(83886080))
 
 	ch := make(chan prometheus.Metric)
 	go func() {
@@ -49,6 +51,51 @@ func TestPgWALCollector(t *testing.T) {
 	expected := []MetricResult{
 		{labels: labelMap{}, value: 47, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 788529152, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 83886080, metricType: dto.MetricType_COUNTER},
+	}
+
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, m)
+		}
+	})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPgWALCollectorArchiveReady(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error opening a stub db connection: %s", err)
+	}
+	defer db.Close()
+
+	inst := &instance{db: db, version: semver.MustParse("12.0.0")}
+
+	columns := []string{"segments", "size"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(47, 788529152)
+	mock.ExpectQuery(sanitizeQuery(pgWALQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(pgWALBytesQuery)).WillReturnRows(sqlmock.NewRows([]string{"bytes"}).AddRow(83886080))
+	mock.ExpectQuery(sanitizeQuery(pgWALArchiveReadyQuery)).WillReturnRows(sqlmock.NewRows([]string{"ready"}).AddRow(12))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c := PGWALCollector{}
+
+		if err := c.Update(context.Background(), inst, ch); err != nil {
+			t.Errorf("Error calling PGWALCollector.Update: %s", err)
+		}
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 47, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 788529152, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 83886080, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 12, metricType: dto.MetricType_GAUGE},
 	}
 
 	convey.Convey("Metrics comparison", t, func() {
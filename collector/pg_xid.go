@@ -0,0 +1,88 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const xidSubsystem = "xid"
+
+func init() {
+	registerCollector(xidSubsystem, defaultDisabled, NewPGXidCollector)
+}
+
+type PGXidCollector struct {
+	log log.Logger
+}
+
+func NewPGXidCollector(config collectorConfig) (Collector, error) {
+	return &PGXidCollector{log: config.logger}, nil
+}
+
+var (
+	xidCurrent = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, xidSubsystem, "current_total"),
+		"Next transaction ID to be assigned, including the epoch. The rate of this counter is the transaction ID consumption rate, subtransactions included",
+		[]string{},
+		prometheus.Labels{},
+	)
+
+	// pg_current_xact_id() and txid_current() assign a transaction ID to the
+	// calling transaction, which would make the exporter consume XIDs itself.
+	// The xmax of the current snapshot is the next XID to be assigned and
+	// can be read without side effects, including on a standby.
+	xidCurrentQuery = `SELECT pg_snapshot_xmax(pg_current_snapshot())::text AS current_xid`
+
+	// pg_current_snapshot() was added in PostgreSQL 13 to replace txid_current_snapshot()
+	xidCurrentQueryPre13 = `SELECT txid_snapshot_xmax(txid_current_snapshot())::text AS current_xid`
+)
+
+func (c *PGXidCollector) Update(ctx context.Context, instance *instance, ch chan<- prometheus.Metric) error {
+	query := xidCurrentQueryPre13
+	if instance.version.GE(semver.MustParse("13.0.0")) {
+		query = xidCurrentQuery
+	}
+
+	db := instance.getDB()
+	row := db.QueryRowContext(ctx, query)
+
+	var currentXid sql.NullString
+	if err := row.Scan(&currentXid); err != nil {
+		return err
+	}
+	if !currentXid.Valid {
+		return ErrNoData
+	}
+
+	// Full transaction IDs are 64 bit, so parse them as unsigned integers
+	// rather than relying on a signed bigint conversion.
+	xid, err := strconv.ParseUint(currentXid.String, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		xidCurrent,
+		prometheus.CounterValue,
+		float64(xid),
+	)
+	return nil
+}
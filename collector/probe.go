@@ -24,32 +24,37 @@ import (
 )
 
 type ProbeCollector struct {
+	ctx        context.Context
 	registry   *prometheus.Registry
 	collectors map[string]Collector
 	logger     log.Logger
 	instance   *instance
+	timeouts   collectorTimeouts
 }
 
-func NewProbeCollector(logger log.Logger, excludeDatabases []string, registry *prometheus.Registry, dsn config.DSN) (*ProbeCollector, error) {
+// NewProbeCollector creates a collector for a single probe of dsn. Only the
+// collectors named in filters are run, or all enabled collectors if filters is
+// empty. ctx bounds the collection and is normally the probe request context.
+func NewProbeCollector(ctx context.Context, logger log.Logger, excludeDatabases []string, registry *prometheus.Registry, dsn config.DSN, filters []string) (*ProbeCollector, error) {
+	f, err := collectorFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	timeouts, err := newCollectorTimeouts(*collectorTimeout, *collectorTimeoutOverrides)
+	if err != nil {
+		return nil, err
+	}
 	collectors := make(map[string]Collector)
 	initiatedCollectorsMtx.Lock()
 	defer initiatedCollectorsMtx.Unlock()
 	for key, enabled := range collectorState {
-		// TODO: Handle filters
-		// if !*enabled || (len(f) > 0 && !f[key]) {
-		// 	continue
-		// }
-		if !*enabled {
+		if !*enabled || (len(f) > 0 && !f[key]) {
 			continue
 		}
 		if collector, ok := initiatedCollectors[key]; ok {
 			collectors[key] = collector
 		} else {
-			collector, err := factories[key](
-				collectorConfig{
-					logger:           log.With(logger, "collector", key),
-					excludeDatabases: excludeDatabases,
-				})
+			collector, err := factories[key](newCollectorConfig(logger, key, excludeDatabases))
 			if err != nil {
 				return nil, err
 			}
@@ -58,16 +63,20 @@ func NewProbeCollector(logger log.Logger, excludeDatabases []string, registry *p
 		}
 	}
 
+	initScrapeCounters(collectors)
+
 	instance, err := newInstance(dsn.GetConnectionString())
 	if err != nil {
 		return nil, err
 	}
 
 	return &ProbeCollector{
+		ctx:        ctx,
 		registry:   registry,
 		collectors: collectors,
 		logger:     logger,
 		instance:   instance,
+		timeouts:   timeouts,
 	}, nil
 }
 
@@ -82,12 +91,14 @@ func (pc *ProbeCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 	defer pc.instance.Close()
+	connectDuration.Observe(pc.instance.connectDuration.Seconds())
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(pc.collectors))
 	for name, c := range pc.collectors {
 		go func(name string, c Collector) {
-			execute(context.TODO(), name, c, pc.instance, ch, pc.logger)
+			err := execute(pc.ctx, name, c, pc.instance, ch, pc.logger, pc.timeouts.get(name))
+			recordScrape(name, err)
 			wg.Done()
 		}(name, c)
 	}
@@ -16,7 +16,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -40,6 +42,15 @@ var (
 
 type Config struct {
 	AuthModules map[string]AuthModule `yaml:"auth_modules"`
+	Targets     []Target              `yaml:"targets"`
+	Metrics     Metrics               `yaml:"metrics"`
+}
+
+// Metrics holds the scrape settings for the /metrics endpoint. They are
+// applied again every time the config file is reloaded.
+type Metrics struct {
+	Collectors []string          `yaml:"collectors"`
+	Labels     map[string]string `yaml:"labels"`
 }
 
 type AuthModule struct {
@@ -54,6 +65,57 @@ type UserPass struct {
 	Password string `yaml:"password"`
 }
 
+// Target holds the scrape settings for /probe targets matching Pattern. The
+// first matching entry in Config.Targets wins.
+type Target struct {
+	Pattern    string            `yaml:"pattern"`
+	AuthModule string            `yaml:"auth_module"`
+	Options    map[string]string `yaml:"options"`
+	Collectors []string          `yaml:"collectors"`
+	Labels     map[string]string `yaml:"labels"`
+	Timeout    time.Duration     `yaml:"timeout"`
+
+	pattern *regexp.Regexp
+}
+
+// Matches reports whether the target pattern matches the whole of target.
+func (t Target) Matches(target string) bool {
+	return t.pattern != nil && t.pattern.MatchString(target)
+}
+
+// TargetFor returns the first target configuration matching target.
+func (c *Config) TargetFor(target string) (Target, bool) {
+	for _, t := range c.Targets {
+		if t.Matches(target) {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
+
+func (c *Config) validate() error {
+	for i := range c.Targets {
+		t := &c.Targets[i]
+		if t.Pattern == "" {
+			return fmt.Errorf("target %d has no pattern", i)
+		}
+		re, err := regexp.Compile("^(?:" + t.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("target %q has an invalid pattern: %s", t.Pattern, err)
+		}
+		t.pattern = re
+		if t.AuthModule != "" {
+			if _, ok := c.AuthModules[t.AuthModule]; !ok {
+				return fmt.Errorf("target %q references unknown auth_module %q", t.Pattern, t.AuthModule)
+			}
+		}
+		if t.Timeout < 0 {
+			return fmt.Errorf("target %q has a negative timeout", t.Pattern)
+		}
+	}
+	return nil
+}
+
 type Handler struct {
 	sync.RWMutex
 	Config *Config
@@ -65,7 +127,11 @@ func (ch *Handler) GetConfig() *Config {
 	return ch.Config
 }
 
-func (ch *Handler) ReloadConfig(f string, logger log.Logger) error {
+// ReloadConfig loads and validates the config file f. Every function in
+// apply is then called with the new config, in order, and the config is
+// only put in place if none of them fails. This lets callers check and
+// build what depends on the config before it is served.
+func (ch *Handler) ReloadConfig(f string, logger log.Logger, apply ...func(*Config) error) error {
 	config := &Config{}
 	var err error
 	defer func() {
@@ -89,6 +155,16 @@ func (ch *Handler) ReloadConfig(f string, logger log.Logger) error {
 		return fmt.Errorf("Error parsing config file %q: %s", f, err)
 	}
 
+	if err = config.validate(); err != nil {
+		return fmt.Errorf("Error validating config file %q: %s", f, err)
+	}
+
+	for _, fn := range apply {
+		if err = fn(config); err != nil {
+			return fmt.Errorf("Error applying config file %q: %s", f, err)
+		}
+	}
+
 	ch.Lock()
 	ch.Config = config
 	ch.Unlock()
@@ -118,3 +194,18 @@ func (m AuthModule) ConfigureTarget(target string) (DSN, error) {
 
 	return dsn, nil
 }
+
+// ConfigureTarget builds the DSN for target using the auth module and then
+// applies the target specific options on top.
+func (t Target) ConfigureTarget(m AuthModule, target string) (DSN, error) {
+	dsn, err := m.ConfigureTarget(target)
+	if err != nil {
+		return DSN{}, err
+	}
+
+	for k, v := range t.Options {
+		dsn.query.Set(k, v)
+	}
+
+	return dsn, nil
+}